@@ -0,0 +1,51 @@
+package restclient
+
+import (
+	"time"
+
+	"github.com/go-resty/resty"
+)
+
+// AccessLogEntry one completed request, for access log style logging
+type AccessLogEntry struct {
+	Method     string
+	URL        string // query params which look like secrets are redacted
+	StatusCode int    // 0 if no response was received
+	BytesIn    int64  // response body bytes, 0 for WithRawBody requests
+	BytesOut   int64  // request body bytes as sent
+	Duration   time.Duration
+	Attempts   int   // 1 plus the retries
+	Err        error // transport error, nil if a response was received
+}
+
+// WithAccessLog call fn once per completed request of the client
+func WithAccessLog(fn func(entry AccessLogEntry)) ClientOption {
+	return func(client *clientImpl) {
+		client.accessLog = fn
+	}
+}
+
+func (client *clientImpl) logAccess(r *resty.Request, method string, start time.Time, resp *resty.Response, err error) {
+	if client.accessLog == nil || r.RawRequest == nil {
+		return
+	}
+
+	entry := AccessLogEntry{
+		Method:   method,
+		URL:      redactURL(r.RawRequest.URL),
+		Duration: client.clock.Now().Sub(start),
+		Attempts: settingsOf(r.Context()).attempts,
+		Err:      err,
+	}
+
+	if r.RawRequest.ContentLength > 0 {
+		entry.BytesOut = r.RawRequest.ContentLength
+	}
+
+	if resp != nil && resp.RawResponse != nil {
+		entry.StatusCode = resp.StatusCode()
+		entry.BytesIn = resp.Size()
+	}
+
+	client.accessLog(entry)
+}
@@ -0,0 +1,57 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAccessLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1}`))
+	}))
+
+	defer server.Close()
+
+	var entries []AccessLogEntry
+
+	accessLog := WithAccessLog(func(entry AccessLogEntry) {
+		entries = append(entries, entry)
+	})
+
+	client := New(server.URL, accessLog)
+
+	require.NoError(t, client.POST("/users", map[string]string{"name": "bob"}, WithQueryParamsFromStruct(map[string]string{"api_key": "k1"})).Error())
+
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+
+	require.Equal(t, http.MethodPost, entry.Method)
+	require.Equal(t, server.URL+"/users?api_key=REDACTED", entry.URL)
+	require.Equal(t, http.StatusOK, entry.StatusCode)
+	require.Equal(t, int64(len(`{"name":"bob"}`)), entry.BytesOut)
+	require.Equal(t, int64(len(`{"id":1}`)), entry.BytesIn)
+	require.Equal(t, 1, entry.Attempts)
+	require.True(t, entry.Duration > 0)
+	require.NoError(t, entry.Err)
+
+	entries = nil
+
+	addr := refusedAddr(t)
+
+	require.Error(t, New("http://"+addr, accessLog, WithRetry(2, time.Millisecond)).GET("/users", nil).Error())
+
+	require.Len(t, entries, 1)
+
+	entry = entries[0]
+
+	require.Equal(t, http.MethodGet, entry.Method)
+	require.Equal(t, "http://"+addr+"/users", entry.URL)
+	require.Equal(t, 0, entry.StatusCode)
+	require.Equal(t, 3, entry.Attempts)
+	require.Error(t, entry.Err)
+}
@@ -0,0 +1,40 @@
+package restclient
+
+import "sync"
+
+// GetMany issues one GET per request with at most concurrency requests in flight,
+// the returned results are in the same order as the input requests.
+//
+// bind a context with WithContext to cancel the batch, requests which are not sent
+// yet when ctx is done fail immediately with the context error
+func (client *clientImpl) GetMany(path string, requests []interface{}, concurrency int, options ...Option) []Result {
+
+	results := make([]Result, len(requests))
+
+	if concurrency <= 0 || concurrency > len(requests) {
+		concurrency = len(requests)
+	}
+
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, request := range requests {
+		slots <- struct{}{}
+
+		wg.Add(1)
+
+		go func(i int, request interface{}) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i] = client.GET(path, request, options...)
+		}(i, request)
+	}
+
+	wg.Wait()
+
+	return results
+}
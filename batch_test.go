@@ -0,0 +1,78 @@
+package restclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type getManyRequest struct {
+	ID int `json:"id"`
+}
+
+func TestGetMany(t *testing.T) {
+	var inflight, peak int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+
+		json.NewEncoder(w).Encode(map[string]string{"id": r.URL.Query().Get("id")})
+	}))
+
+	defer server.Close()
+
+	var requests []interface{}
+
+	for i := 0; i < 10; i++ {
+		requests = append(requests, &getManyRequest{ID: i})
+	}
+
+	results := New(server.URL).GetMany("/item", requests, 3)
+
+	require.Len(t, results, len(requests))
+
+	for i, result := range results {
+		require.True(t, result.OK())
+
+		var id string
+		require.NoError(t, result.Value("id", &id))
+		require.Equal(t, strconv.Itoa(requests[i].(*getManyRequest).ID), id)
+	}
+
+	require.True(t, peak <= 3)
+}
+
+func TestGetManyCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := New(server.URL).GetMany("/item", []interface{}{nil, nil}, 1, WithContext(ctx))
+
+	for _, result := range results {
+		require.True(t, result.Fail())
+		require.Error(t, result.Error())
+	}
+}
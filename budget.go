@@ -0,0 +1,63 @@
+package restclient
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// retryBudget token bucket limiting retries to a ratio of the requests: every request
+// deposits ratio tokens and every retry withdraws one, on top of minPerSec retries a second
+type retryBudget struct {
+	sync.Mutex
+	ratio     float64
+	minPerSec int
+	tokens    float64
+	window    time.Time // start of the current minPerSec window
+	windowUse int
+}
+
+// retryBudgetBank max requests worth of deposits the budget keeps
+const retryBudgetBank = 100
+
+// WithRetryBudget allow at most ratio retries per request on average (e.g. 0.1 for 10% extra load)
+// plus minPerSec retries a second, so a widespread failure doesn't turn into a retry storm.
+// failures beyond the budget are returned without retrying
+func WithRetryBudget(ratio float64, minPerSec int) ClientOption {
+	return func(client *clientImpl) {
+		client.retry.budget = &retryBudget{
+			ratio:     ratio,
+			minPerSec: minPerSec,
+		}
+	}
+}
+
+func (budget *retryBudget) deposit() {
+	budget.Lock()
+	defer budget.Unlock()
+
+	budget.tokens = math.Min(budget.tokens+budget.ratio, budget.ratio*retryBudgetBank)
+}
+
+func (budget *retryBudget) withdraw(now time.Time) bool {
+	budget.Lock()
+	defer budget.Unlock()
+
+	if now.Sub(budget.window) >= time.Second {
+		budget.window = now
+		budget.windowUse = 0
+	}
+
+	if budget.windowUse < budget.minPerSec {
+		budget.windowUse++
+		return true
+	}
+
+	// tolerate the float rounding of the summed deposits, 10 deposits of 0.1 make a token
+	if budget.tokens >= 1-1e-9 {
+		budget.tokens--
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package restclient
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	client := New("http://"+refusedAddr(t), WithRetry(3, time.Second), WithRetryBudget(0.1, 0), withClock(newFakeClock()))
+
+	for i := 0; i < 20; i++ {
+		require.Error(t, client.GET("/", nil).Error())
+	}
+
+	// 20 requests deposit 2 tokens, so only 2 of the 60 possible retries are sent
+	require.Equal(t, int64(22), client.Stats().Requests)
+}
+
+func TestRetryBudgetMinPerSec(t *testing.T) {
+	clock := newFakeClock()
+
+	budget := &retryBudget{ratio: 0, minPerSec: 2}
+
+	require.True(t, budget.withdraw(clock.Now()))
+	require.True(t, budget.withdraw(clock.Now()))
+	require.False(t, budget.withdraw(clock.Now()))
+
+	clock.After(time.Second)
+
+	require.True(t, budget.withdraw(clock.Now()))
+}
+
+func TestRetryBudgetConcurrent(t *testing.T) {
+	budget := &retryBudget{ratio: 0.5}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			budget.deposit()
+		}()
+	}
+
+	wg.Wait()
+
+	withdrawn := 0
+
+	for budget.withdraw(time.Now()) {
+		withdrawn++
+	}
+
+	require.Equal(t, 50, withdrawn)
+}
@@ -1,12 +1,19 @@
 package restclient
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/dynamicgo/xerrors/apierr"
 
@@ -26,13 +33,26 @@ type Auth interface {
 // Client .
 type Client interface {
 	POST(path string, request interface{}, options ...Option) Result
+	POSTForm(path string, form url.Values, options ...Option) Result
 	GET(path string, request interface{}, options ...Option) Result
 	DELETE(path string, request interface{}, options ...Option) Result
+	POSTE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error)
+	GETE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error)
+	DELETEE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error)
+	BuildURL(path string, request interface{}, options ...Option) (string, error)
+	GetMany(path string, requests []interface{}, concurrency int, options ...Option) []Result
+	Stats() ClientStats
+	PollUntil(path string, request interface{}, done func(Result) (bool, error), interval, timeout time.Duration, options ...Option) (Result, error)
+	UploadResumable(path string, r io.ReaderAt, size int64, chunkSize int64, options ...Option) Result
+	Shutdown(ctx context.Context) error
 }
 
 // Option .
 type Option func(request *http.Request)
 
+// ClientOption client level option
+type ClientOption func(client *clientImpl)
+
 // WithAuth add auth option
 func WithAuth(auth Auth) Option {
 	return func(request *http.Request) {
@@ -40,6 +60,74 @@ func WithAuth(auth Auth) Option {
 	}
 }
 
+// defaultErrorBodyLimit max bytes of the response body embedded in error messages
+const defaultErrorBodyLimit = 1024
+
+type settingsKey struct{}
+
+// requestSettings per request settings which can't be carried by http.Request itself,
+// they travel with the request context so options can reach them
+type requestSettings struct {
+	err               error // first error raised by an option, fails the request before sending
+	maxRequestBytes   int64
+	headerSuccess     func(http.Header) bool
+	msgpack           Codec          // msgpack codec of the client, nil if not configured
+	jsonpath          JSONPathEngine // jsonpath engine of the client, nil if not configured
+	msgpackBody       bool
+	removeHeaders     []string // removed after all options run
+	errorBodyLimit    int      // 0 means defaultErrorBodyLimit
+	bodyFunc          func(ctx context.Context) (interface{}, error)
+	urlFuncs          []func(u *url.URL) // run after all options
+	retryCount        *int               // overrides the client retry count
+	rawBody           bool               // leave the response body unread for streaming
+	naming            KeyNaming
+	queries           []interface{} // merged into the query after all options run
+	readTimeout       time.Duration
+	autoGzip          *int              // gzip bodies larger than this, nil disables
+	pathParams        map[string]string // substituted into the {key} path segments
+	includeRequest    bool              // append the request to Error()
+	redactRequest     bool
+	requestBody       []byte // captured before sending when includeRequest or replayBody is set
+	replayBody        bool   // the body is a reader, Replay sends the captured bytes
+	metricLabels      map[string]string
+	maxPages          int // CollectAll page cap, 0 means defaultMaxPages
+	priority          int
+	connectionClose   bool
+	tokenRefreshed    bool // the request is the retry after a token refresh
+	expectContinue    time.Duration
+	transportOverride *TransportOverride
+	attempts          int                           // sent attempts, set once the request completes
+	record            func(request RecordedRequest) // set by Recorder, called once the request is sent
+	headerMappings    []map[string]string           // inbound to outbound header names, applied after all options
+}
+
+func withSettings(ctx context.Context, settings *requestSettings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+// settingsOf get request settings, requests not created by this package get a detached one
+func settingsOf(ctx context.Context) *requestSettings {
+	if settings, ok := ctx.Value(settingsKey{}).(*requestSettings); ok {
+		return settings
+	}
+
+	return &requestSettings{}
+}
+
+// WithContext bind request with ctx, the request is cancelled when ctx is done
+func WithContext(ctx context.Context) Option {
+	return func(request *http.Request) {
+		*request = *request.WithContext(withSettings(ctx, settingsOf(request.Context())))
+	}
+}
+
+// WithMaxRequestBytes fail the request locally when the serialized body exceeds n bytes
+func WithMaxRequestBytes(n int64) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).maxRequestBytes = n
+	}
+}
+
 // WithJWToken .
 func WithJWToken(token string) Option {
 	return func(request *http.Request) {
@@ -47,63 +135,393 @@ func WithJWToken(token string) Option {
 	}
 }
 
+// WithAuthorization set the Authorization header verbatim
+func WithAuthorization(value string) Option {
+	return func(request *http.Request) {
+		request.Header.Set("Authorization", value)
+	}
+}
+
+// WithTokenScheme set the Authorization header as "<scheme> <token>"
+func WithTokenScheme(scheme, token string) Option {
+	return WithAuthorization(fmt.Sprintf("%s %s", scheme, token))
+}
+
+// WithQueryParamsFromStruct merge the query params derived from v into the request,
+// v is flattened the same way as GET/DELETE requests
+func WithQueryParamsFromStruct(v interface{}) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+		settings.queries = append(settings.queries, v)
+	}
+}
+
+func mergeQuery(request *http.Request, params url.Values) {
+	values := request.URL.Query()
+
+	for k, v := range params {
+		values[k] = v
+	}
+
+	request.URL.RawQuery = values.Encode()
+}
+
+// WithHeaderSuccessFunc let OK/Fail consult the response headers in addition to the status code,
+// for APIs which always answer 200 and signal errors by header
+func WithHeaderSuccessFunc(f func(header http.Header) bool) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).headerSuccess = f
+	}
+}
+
+// WithHostHeader send host as the Host header while the connection still targets the url host,
+// e.g. address a service by ip with a virtual host name
+func WithHostHeader(host string) Option {
+	return func(request *http.Request) {
+		request.Host = host
+	}
+}
+
+// WithReferer set the Referer header, referer must be an absolute http(s) url
+func WithReferer(referer string) Option {
+	return func(request *http.Request) {
+		u, err := url.Parse(referer)
+
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			settings := settingsOf(request.Context())
+
+			if settings.err == nil {
+				settings.err = fmt.Errorf("invalid referer %q, expect an absolute http(s) url", referer)
+			}
+
+			return
+		}
+
+		request.Header.Set("Referer", referer)
+	}
+}
+
+// WithoutHeader remove the header key from the request, it runs after all other options
+// so it also drops headers set by the client default options
+func WithoutHeader(key string) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+		settings.removeHeaders = append(settings.removeHeaders, key)
+	}
+}
+
+// WithConnectionClose close the connection after this request instead of returning it to the pool
+func WithConnectionClose() Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).connectionClose = true
+		request.Header.Set("Connection", "close")
+	}
+}
+
+// WithDefaultOptions apply options to every request of the client, before the request options
+func WithDefaultOptions(options ...Option) ClientOption {
+	return func(client *clientImpl) {
+		client.options = append(client.options, options...)
+	}
+}
+
+// WithMethodDefaults apply options to every request of the client sent with method,
+// after the client default options and before the request options
+func WithMethodDefaults(method string, options ...Option) ClientOption {
+	return func(client *clientImpl) {
+		if client.methodOptions == nil {
+			client.methodOptions = make(map[string][]Option)
+		}
+
+		method = strings.ToUpper(method)
+
+		client.methodOptions[method] = append(client.methodOptions[method], options...)
+	}
+}
+
+// WithStatusHandler pass every result with status code to handler and return what it returns
+// instead, e.g. follow the Location of a 202 Accepted until the job is done. handlers may issue
+// requests themselves, with the client or through Result.Replay, those go through the handlers too
+func WithStatusHandler(code int, handler func(Result) Result) ClientOption {
+	return func(client *clientImpl) {
+		if client.statusHandlers == nil {
+			client.statusHandlers = make(map[int]func(Result) Result)
+		}
+
+		client.statusHandlers[code] = handler
+	}
+}
+
+// WithErrorBodyLimit truncate the response body embedded in Error() to n bytes,
+// the default is 1KB and n < 0 disables truncation. Bytes() still returns the full body
+func WithErrorBodyLimit(n int) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).errorBodyLimit = n
+	}
+}
+
+// WithBodyFunc produce the request body with f right before the request is sent, again
+// for every retry attempt, replacing the request passed to POST. f gets the request context
+func WithBodyFunc(f func(ctx context.Context) (interface{}, error)) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).bodyFunc = f
+	}
+}
+
+// WithAccept send an Accept header preferring types in the given order,
+// e.g. "application/json;q=1.0, application/xml;q=0.5". see Result.Into for decoding
+func WithAccept(types ...string) Option {
+	return func(request *http.Request) {
+		accept := make([]string, 0, len(types))
+
+		for i, t := range types {
+			accept = append(accept, fmt.Sprintf("%s;q=%.1f", t, float64(len(types)-i)/float64(len(types))))
+		}
+
+		request.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+}
+
+// WithPathParam substitute value, escaped, for the {key} segment of the request path
+func WithPathParam(key, value string) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+
+		if settings.pathParams == nil {
+			settings.pathParams = make(map[string]string)
+		}
+
+		settings.pathParams[key] = value
+	}
+}
+
+func substitutePath(request *http.Request, params map[string]string) error {
+	escaped := request.URL.EscapedPath()
+
+	for k, v := range params {
+		escaped = strings.Replace(escaped, "%7B"+k+"%7D", url.PathEscape(v), -1)
+	}
+
+	path, err := url.PathUnescape(escaped)
+
+	if err != nil {
+		return err
+	}
+
+	request.URL.Path = path
+	request.URL.RawPath = escaped
+
+	return nil
+}
+
+// Path join segments into a request path, each segment formatted with %v and percent-encoded,
+// slashes included, e.g. Path("users", id, "orders") is "/users/42/orders". "." and ".."
+// segments are encoded too so they can't walk up the path
+func Path(segments ...interface{}) string {
+	var builder strings.Builder
+
+	for _, segment := range segments {
+		escaped := url.PathEscape(fmt.Sprintf("%v", segment))
+
+		if escaped == "." || escaped == ".." {
+			escaped = strings.Replace(escaped, ".", "%2E", -1)
+		}
+
+		builder.WriteString("/")
+		builder.WriteString(escaped)
+	}
+
+	return builder.String()
+}
+
+// WithURLFunc let f rewrite the final request url, it runs after all other options
+// so query params merged by them are visible, e.g. to sign the url
+func WithURLFunc(f func(u *url.URL)) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+		settings.urlFuncs = append(settings.urlFuncs, f)
+	}
+}
+
 // Result .
 type Result interface {
 	OK() bool
 	Fail() bool
+	Is(code int) bool
+	Expect(status int, contentType string) error
 	Error() error
 	Response() *resty.Response
+	Bytes() []byte
 	Value(key string, result interface{}) error
+	ValueBytes(key string) ([]byte, error)
+	Scan(pairs ...ScanPair) error
 	Values() map[string]interface{}
+	Any() (interface{}, error)
+	Into(result interface{}) error
+	MustInto(result interface{})
+	MsgPack(result interface{}) error
+	JSONPath(expr string, result interface{}) error
+	Replay(options ...Option) Result
+	ProblemDetails() (*ProblemDetails, error)
+	BodyReader() (io.ReadCloser, error)
 }
 
 type clientImpl struct {
 	sync.RWMutex
-	url  string // url
-	auth Auth
+	url            string // url
+	auth           Auth
+	resty          *resty.Client
+	retry          retryPolicy
+	msgpack        Codec
+	jsonpath       JSONPathEngine
+	stats          clientStats
+	options        []Option            // default options applied before the request options
+	methodOptions  map[string][]Option // per method default options applied after options
+	slow           slowRequest
+	clock          clock
+	naming         KeyNaming
+	transport      *transport
+	queryEncoder   QueryEncoder
+	statusHandlers map[int]func(Result) Result
+	metrics        func(metric RequestMetric)
+	limiter        *prioritySemaphore // nil unless WithMaxConcurrency
+	accessLog      func(entry AccessLogEntry)
+	shutdown       *shutdown
 }
 
 type resultImpl struct {
-	err    error
-	resp   *resty.Response
-	values map[string]interface{}
+	err       error
+	resp      *resty.Response
+	values    map[string]interface{}
+	valuesErr error
+	settings  *requestSettings
+	replay    func(options ...Option) Result
 }
 
-func newResult(err error, resp *resty.Response) Result {
-	return &resultImpl{
-		err:  err,
-		resp: resp,
+func newResult(err error, resp *resty.Response) *resultImpl {
+	result := &resultImpl{
+		err:      err,
+		resp:     resp,
+		settings: &requestSettings{},
 	}
+
+	if resp != nil && resp.Request != nil {
+		result.settings = settingsOf(resp.Request.Context())
+	}
+
+	return result
 }
 
 func (result *resultImpl) Response() *resty.Response {
 	return result.resp
 }
 
-func (result *resultImpl) extractValues() {
-	if result.values != nil {
-		return
+// Bytes the raw response body, nil if no response was received
+func (result *resultImpl) Bytes() []byte {
+	if result.resp == nil {
+		return nil
+	}
+
+	return result.resp.Body()
+}
+
+// DecodeError a response body, or the Value of Key, which failed to decode
+type DecodeError struct {
+	Key     string // empty when decoding the whole body
+	Body    []byte // the data which failed to decode
+	Cause   error
+	op      string
+	snippet string // the body shown in the message
+}
+
+// newDecodeError op names the failed step in Error(), followed by snippet of the body
+func newDecodeError(key string, op string, body []byte, snippet string, cause error) *DecodeError {
+	return &DecodeError{
+		Key:     key,
+		Body:    body,
+		Cause:   cause,
+		op:      op,
+		snippet: snippet,
+	}
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("%s err %s\n%s", err.op, err.Cause, err.snippet)
+}
+
+// Unwrap the cause
+func (err *DecodeError) Unwrap() error {
+	return err.Cause
+}
+
+// extractValues decode the body as a json object once, a body which isn't json
+// (e.g. an html error page from a gateway) is reported instead of read as no values
+func (result *resultImpl) extractValues() error {
+	if result.values != nil || result.valuesErr != nil {
+		return result.valuesErr
 	}
 
 	if result.resp == nil {
-		return
+		result.valuesErr = fmt.Errorf("no response to decode: %s", result.Error())
+		return result.valuesErr
 	}
 
 	values := make(map[string]interface{})
 
-	json.Unmarshal(result.resp.Body(), &values)
+	if err := json.Unmarshal(result.resp.Body(), &values); err != nil {
+		op := fmt.Sprintf("decode response(content type %q)", result.resp.Header().Get("Content-Type"))
+		result.valuesErr = newDecodeError("", op, result.resp.Body(), result.bodySnippet(), err)
+
+		return result.valuesErr
+	}
 
 	result.values = values
 
+	return nil
 }
 
 func (result *resultImpl) OK() bool {
-	return result.err == nil && result.resp.StatusCode() == http.StatusOK
+	if result.err != nil || result.resp.StatusCode() != http.StatusOK {
+		return false
+	}
+
+	if result.settings.headerSuccess != nil {
+		return result.settings.headerSuccess(result.resp.Header())
+	}
+
+	return true
 }
 func (result *resultImpl) Fail() bool {
 	return !result.OK()
 }
 
+// Expect check the response has status and the media type contentType, parameters like
+// charset are ignored and an empty contentType matches any
+func (result *resultImpl) Expect(status int, contentType string) error {
+	if result.err != nil {
+		return result.err
+	}
+
+	if result.resp == nil || result.resp.RawResponse == nil {
+		return fmt.Errorf("expect status %d, no response received", status)
+	}
+
+	got := result.resp.Header().Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(got)
+
+	if result.resp.StatusCode() == status && (contentType == "" || strings.EqualFold(mediaType, contentType)) {
+		return nil
+	}
+
+	return fmt.Errorf("%s %s expect status %d content type %q, got status code(%s) content type %q",
+		result.resp.Request.Method, result.requestURL(), status, contentType, result.resp.Status(), got)
+}
+
+// Is report whether a response with status code was received
+func (result *resultImpl) Is(code int) bool {
+	return result.resp != nil && result.resp.RawResponse != nil && result.resp.StatusCode() == code
+}
+
 func (result *resultImpl) Error() error {
 
 	if result.OK() {
@@ -116,23 +534,68 @@ func (result *resultImpl) Error() error {
 
 	if result.resp != nil {
 
+		// a 200 failing only by WithHeaderSuccessFunc, its body is no error response
+		if result.resp.StatusCode() == http.StatusOK && result.settings.headerSuccess != nil {
+			return apierr.New(1, result.statusMessage()+" rejected by response header check"+result.requestMessage())
+		}
+
 		var rc errresp
 
 		err := json.Unmarshal(result.resp.Body(), &rc)
 
 		if err != nil {
-			return apierr.New(1, string(result.resp.Body()))
+			return apierr.New(1, result.statusMessage()+result.requestMessage())
 		}
 
-		return apierr.New(rc.Code, rc.Msg)
+		return apierr.New(rc.Code, rc.Msg+result.requestMessage())
 	}
 
 	return nil
 }
 
+// statusMessage format the failed response with the request method and url,
+// the body section is omitted when the response body is empty
+func (result *resultImpl) statusMessage() string {
+	msg := fmt.Sprintf("%s %s status code(%s)", result.resp.Request.Method, result.requestURL(), result.resp.Status())
+
+	if len(result.resp.Body()) > 0 {
+		msg = fmt.Sprintf("%s %s", msg, result.bodySnippet())
+	}
+
+	return msg
+}
+
+// bodySnippet the response body truncated to the error body limit
+func (result *resultImpl) bodySnippet() string {
+	return result.snippet(result.resp.Body())
+}
+
+// snippet truncate body to the error body limit
+func (result *resultImpl) snippet(body []byte) string {
+	limit := result.settings.errorBodyLimit
+
+	if limit == 0 {
+		limit = defaultErrorBodyLimit
+	}
+
+	if limit > 0 && len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+
+	return string(body)
+}
+
 func (result *resultImpl) Value(key string, v interface{}) error {
 
-	result.extractValues()
+	v, err := decodeTarget("Value", v)
+
+	if err != nil {
+		return err
+	}
+
+	if err := result.extractValues(); err != nil {
+		return err
+	}
 
 	data, ok := result.values[key]
 
@@ -143,44 +606,295 @@ func (result *resultImpl) Value(key string, v interface{}) error {
 	buff, err := json.Marshal(data)
 
 	if err != nil {
-		return fmt.Errorf("unmarshal result(%s) err %s\n%s", key, err, string(result.resp.Body()))
+		return newDecodeError(key, fmt.Sprintf("unmarshal result(%s)", key), result.resp.Body(), string(result.resp.Body()), err)
 	}
 
-	if err := json.Unmarshal(buff, v); err != nil {
-		return fmt.Errorf("unmarshal result(%s) err %s\n%s", key, err, string(buff))
+	if err := unmarshalJSON(buff, v, result.settings.naming); err != nil {
+		return newDecodeError(key, fmt.Sprintf("unmarshal result(%s)", key), buff, string(buff), err)
 	}
 
 	return nil
 }
 
+// ScanPair a Scan key and the target its value is decoded into
+type ScanPair struct {
+	Key    string
+	Target interface{}
+}
+
+// ScanKeyError the error of a Scan key
+type ScanKeyError struct {
+	Key string
+	Err error
+}
+
+// ScanError the keys of a Scan which failed, in the order of the pairs
+type ScanError struct {
+	Failed []ScanKeyError
+	total  int
+}
+
+func (err *ScanError) Error() string {
+	lines := make([]string, 0, len(err.Failed))
+
+	for _, failed := range err.Failed {
+		lines = append(lines, fmt.Sprintf("%s: %s", failed.Key, failed.Err))
+	}
+
+	return fmt.Sprintf("scan result err %d of %d keys failed\n%s", len(err.Failed), err.total, strings.Join(lines, "\n"))
+}
+
+// Unwrap the errors of the failed keys, errors.As finds e.g. the *DecodeError of a key
+func (err *ScanError) Unwrap() []error {
+	errs := make([]error, 0, len(err.Failed))
+
+	for _, failed := range err.Failed {
+		errs = append(errs, failed.Err)
+	}
+
+	return errs
+}
+
+// Scan decode the value of each pair key into its target like Value does, the body is parsed
+// once for all keys. every key is tried, a *ScanError holds the keys which failed
+func (result *resultImpl) Scan(pairs ...ScanPair) error {
+	var failed []ScanKeyError
+
+	for _, pair := range pairs {
+		if err := result.Value(pair.Key, pair.Target); err != nil {
+			failed = append(failed, ScanKeyError{Key: pair.Key, Err: err})
+		}
+	}
+
+	if len(failed) > 0 {
+		return &ScanError{Failed: failed, total: len(pairs)}
+	}
+
+	return nil
+}
+
+// ValueBytes the base64 string at key decoded, standard and url safe alphabets are accepted
+// with or without padding
+func (result *resultImpl) ValueBytes(key string) ([]byte, error) {
+	var encoded string
+
+	if err := result.Value(key, &encoded); err != nil {
+		return nil, err
+	}
+
+	encodings := []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding}
+
+	for _, encoding := range encodings {
+		if data, err := encoding.DecodeString(encoded); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("decode result(%s) err not a base64 string\n%s", key, encoded)
+}
+
+// Into unmarshal the whole response body into v, the decoder is picked by the
+// response Content-Type: xml, msgpack when the client has the codec, json otherwise
+func (result *resultImpl) Into(v interface{}) error {
+	v, err := decodeTarget("Into", v)
+
+	if err != nil {
+		return err
+	}
+
+	if result.resp == nil {
+		return fmt.Errorf("unmarshal result err %s", result.Error())
+	}
+
+	if err := result.unmarshaler()(result.resp.Body(), v); err != nil {
+		return newDecodeError("", "unmarshal result", result.resp.Body(), string(result.resp.Body()), err)
+	}
+
+	return nil
+}
+
+// decodeTarget check v can be decoded into, a non-nil map is decoded into in place
+func decodeTarget(method string, v interface{}) (interface{}, error) {
+	value := reflect.ValueOf(v)
+
+	switch {
+	case v == nil:
+		return nil, fmt.Errorf("%s requires a non-nil pointer, got nil", method)
+	case value.Kind() == reflect.Map && !value.IsNil():
+		target := reflect.New(value.Type())
+		target.Elem().Set(value)
+
+		return target.Interface(), nil
+	case value.Kind() != reflect.Ptr:
+		return nil, fmt.Errorf("%s requires a non-nil pointer, got %T", method, v)
+	case value.IsNil():
+		return nil, fmt.Errorf("%s requires a non-nil pointer, got nil %T", method, v)
+	}
+
+	return v, nil
+}
+
+// Replay issue the request which produced this result again, with the same method, url,
+// body and options plus the given options, e.g. after refreshing a token. a reader body is
+// sent again with the bytes read the first time, which needs a bytes.Buffer, bytes.Reader or
+// strings.Reader, other readers can't be replayed
+func (result *resultImpl) Replay(options ...Option) Result {
+	if result.replay == nil {
+		return newResult(fmt.Errorf("result can't be replayed"), nil)
+	}
+
+	return result.replay(options...)
+}
+
+// Any decode the json response body whatever its root is, object, array or scalar
+func (result *resultImpl) Any() (interface{}, error) {
+	var v interface{}
+
+	if err := result.Into(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
 func (result *resultImpl) Values() map[string]interface{} {
+	result.extractValues()
+
 	return result.values
 }
 
 // New .
-func New(url string) Client {
-	return &clientImpl{
-		url: url,
+func New(url string, options ...ClientOption) Client {
+	client := &clientImpl{
+		url:   url,
+		resty: resty.New(),
+		retry: retryPolicy{
+			retryable: defaultRetryableErrors,
+		},
+		clock:        realClock{},
+		transport:    newTransport(),
+		queryEncoder: FlatQuery,
+		shutdown:     newShutdown(),
+	}
+
+	if base, ok := client.transport.base.(*http.Transport); ok {
+		client.stats.countBytes(base)
+	}
+
+	for _, option := range options {
+		option(client)
 	}
+
+	client.resty.SetPreRequestHook(client.preRequest)
+	client.resty.SetTransport(client.transport)
+
+	return client
 }
 
-func (client *clientImpl) POST(path string, request interface{}, options ...Option) Result {
+// preRequest called by resty after the body is serialized and right before the request is sent
+func (client *clientImpl) preRequest(c *resty.Client, r *resty.Request) error {
+	settings := settingsOf(r.Context())
 
-	r := resty.R().SetBody(request) //.Post(fmt.Sprintf("%s/%s", client.url, path))
+	if (settings.includeRequest || settings.record != nil || settings.replayBody) && r.RawRequest.GetBody != nil {
+		if err := captureBody(r.RawRequest, settings); err != nil {
+			return err
+		}
+	}
 
-	for _, option := range options {
-		option(r.RawRequest)
+	if settings.autoGzip != nil && r.RawRequest.ContentLength > int64(*settings.autoGzip) && r.RawRequest.GetBody != nil {
+		if err := gzipBody(r.RawRequest); err != nil {
+			return err
+		}
+	}
+
+	if settings.connectionClose {
+		r.RawRequest.Close = true
+	}
+
+	if settings.maxRequestBytes > 0 && r.RawRequest.ContentLength > settings.maxRequestBytes {
+		return fmt.Errorf("request too large: body size %d exceeds limit %d", r.RawRequest.ContentLength, settings.maxRequestBytes)
 	}
 
+	client.stats.request()
+
+	return nil
+}
+
+// newRequest create resty request for method and path, the options are applied
+// to the outgoing http request before it is handed over to resty
+func (client *clientImpl) newRequest(method string, path string, query interface{}, options []Option) (*resty.Request, string, error) {
 	url, err := client.checkURL(fmt.Sprintf("%s%s", client.url, path))
 
 	if err != nil {
-		return newResult(err, nil)
+		return nil, "", err
+	}
+
+	request, err := http.NewRequest(method, url, nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	request = request.WithContext(withSettings(request.Context(), &requestSettings{
+		msgpack:  client.msgpack,
+		jsonpath: client.jsonpath,
+		naming:   client.naming,
+	}))
+
+	for _, option := range client.options {
+		option(request)
+	}
+
+	for _, option := range client.methodOptions[method] {
+		option(request)
+	}
+
+	for _, option := range options {
+		option(request)
+	}
+
+	settings := settingsOf(request.Context())
+
+	if settings.err != nil {
+		return nil, "", settings.err
+	}
+
+	if err := substitutePath(request, settings.pathParams); err != nil {
+		return nil, "", err
+	}
+
+	for _, v := range append([]interface{}{query}, settings.queries...) {
+		params, err := client.queryEncoder.Encode(v, settings.naming)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		mergeQuery(request, params)
+	}
+
+	for _, f := range settings.urlFuncs {
+		f(request.URL)
+	}
+
+	propagateHeaders(request, settings.headerMappings)
+
+	for _, key := range settings.removeHeaders {
+		request.Header.Del(key)
 	}
 
-	resp, err := r.Post(url)
+	r := client.resty.R().SetContext(client.stats.trace(request.Context()))
 
-	return newResult(err, resp)
+	r.Header = request.Header
+
+	// resty moves the Host header onto the outgoing request Host field
+	if request.Host != request.URL.Host {
+		r.Header.Set("Host", request.Host)
+	}
+
+	r.SetDoNotParseResponse(settings.rawBody)
+
+	return r, request.URL.String(), nil
 }
 
 func (client *clientImpl) checkURL(s string) (string, error) {
@@ -190,15 +904,22 @@ func (client *clientImpl) checkURL(s string) (string, error) {
 		return "", err
 	}
 
-	u.Path = filepath.Clean(u.Path)
+	// clean the escaped path, so escaped slashes of a segment are kept
+	escaped := filepath.ToSlash(filepath.Clean(u.EscapedPath()))
+
+	if u.Path, err = url.PathUnescape(escaped); err != nil {
+		return "", err
+	}
+
+	u.RawPath = escaped
 
 	return u.String(), nil
 }
 
-func (client *clientImpl) requestToMap(request interface{}) (map[string]string, error) {
+func requestToMap(request interface{}, naming KeyNaming) (map[string]string, error) {
 	var params map[string]interface{}
 
-	buff, err := json.Marshal(request)
+	buff, err := marshalJSON(request, naming)
 
 	if err != nil {
 		return nil, err
@@ -219,52 +940,168 @@ func (client *clientImpl) requestToMap(request interface{}) (map[string]string,
 	return r, nil
 }
 
+func (client *clientImpl) POST(path string, request interface{}, options ...Option) Result {
+	return client.do(http.MethodPost, path, request, options)
+}
+
+// POSTForm POST form url encoded as application/x-www-form-urlencoded
+func (client *clientImpl) POSTForm(path string, form url.Values, options ...Option) Result {
+	contentType := func(request *http.Request) {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	return client.do(http.MethodPost, path, form.Encode(), append([]Option{contentType}, options...))
+}
+
 func (client *clientImpl) GET(path string, request interface{}, options ...Option) Result {
+	return client.do(http.MethodGet, path, request, options)
+}
 
-	params, err := client.requestToMap(request)
+func (client *clientImpl) DELETE(path string, request interface{}, options ...Option) Result {
+	return client.do(http.MethodDelete, path, request, options)
+}
 
-	if err != nil {
-		return newResult(err, nil)
+// BuildURL the url a GET of path with request would be sent to, without sending it
+func (client *clientImpl) BuildURL(path string, request interface{}, options ...Option) (string, error) {
+	_, url, err := client.newRequest(http.MethodGet, path, request, options)
+
+	return url, err
+}
+
+// POSTE like POST bound to ctx, the transport error is returned as error, while the
+// result carries the response for status inspection
+func (client *clientImpl) POSTE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return client.doE(ctx, http.MethodPost, path, request, options)
+}
+
+// GETE like GET bound to ctx, see POSTE
+func (client *clientImpl) GETE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return client.doE(ctx, http.MethodGet, path, request, options)
+}
+
+// DELETEE like DELETE bound to ctx, see POSTE
+func (client *clientImpl) DELETEE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return client.doE(ctx, http.MethodDelete, path, request, options)
+}
+
+func (client *clientImpl) doE(ctx context.Context, method string, path string, request interface{}, options []Option) (Result, error) {
+	result := client.do(method, path, request, append([]Option{WithContext(ctx)}, options...))
+
+	// a status handler may hand back a result of its own
+	if impl, ok := result.(*resultImpl); ok {
+		return result, impl.err
 	}
 
-	r := resty.R().SetQueryParams(params)
+	return result, nil
+}
 
-	for _, option := range options {
-		option(r.RawRequest)
+// do send the request, the result keeps the call to be replayed
+func (client *clientImpl) do(method string, path string, request interface{}, options []Option) Result {
+	_, reader := request.(io.Reader)
+	reader = reader && method == http.MethodPost
+
+	var settings *requestSettings
+
+	// a reader body is drained by the send, keep the bytes sent for the replay
+	capture := func(request *http.Request) {
+		settings = settingsOf(request.Context())
+		settings.replayBody = reader
 	}
 
-	url, err := client.checkURL(fmt.Sprintf("%s%s", client.url, path))
+	result := client.send(method, path, request, append(options[:len(options):len(options)], capture))
 
-	if err != nil {
-		return newResult(err, nil)
+	result.replay = func(more ...Option) Result {
+		body := request
+
+		if reader {
+			if settings == nil || settings.requestBody == nil {
+				return newResult(fmt.Errorf("result can't be replayed, the body reader can't be read again"), nil)
+			}
+
+			body = settings.requestBody
+		}
+
+		return client.do(method, path, body, append(options[:len(options):len(options)], more...))
 	}
 
-	resp, err := r.Get(url)
+	if result.resp != nil {
+		if handler, ok := client.statusHandlers[result.resp.StatusCode()]; ok {
+			return handler(result)
+		}
+	}
 
-	return newResult(err, resp)
+	return result
 }
 
-func (client *clientImpl) DELETE(path string, request interface{}, options ...Option) Result {
+// send the request as the body of a POST, or as the query params otherwise
+func (client *clientImpl) send(method string, path string, request interface{}, options []Option) *resultImpl {
+	query := request
+
+	if method == http.MethodPost {
+		query = nil
+	}
 
-	params, err := client.requestToMap(request)
+	r, url, err := client.newRequest(method, path, query, options)
 
 	if err != nil {
 		return newResult(err, nil)
 	}
 
-	r := resty.R().SetQueryParams(params)
+	// a WithBodyFunc body is produced by execute, once the request holds its slot
+	if method == http.MethodPost && settingsOf(r.Context()).bodyFunc == nil {
+		if err := client.setBody(r, request); err != nil {
+			return newResult(err, nil)
+		}
+	}
 
-	for _, option := range options {
-		option(r.RawRequest)
+	if !client.shutdown.enter() {
+		return newResult(fmt.Errorf("client is shut down"), nil)
 	}
 
-	url, err := client.checkURL(fmt.Sprintf("%s%s", client.url, path))
+	defer client.shutdown.inflight.Done()
 
-	if err != nil {
-		return newResult(err, nil)
+	ctx, cancel := client.shutdown.bind(r.Context())
+
+	r.SetContext(ctx)
+
+	if client.limiter != nil {
+		if err := client.limiter.acquire(r.Context(), settingsOf(r.Context()).priority); err != nil {
+			cancel()
+			return newResult(err, nil)
+		}
+
+		defer client.limiter.release()
+	}
+
+	start := client.clock.Now()
+
+	resp, err := client.execute(r, method, url)
+
+	if resp != nil {
+		client.transport.restoreEncoding(resp.RawResponse)
 	}
 
-	resp, err := r.Delete(url)
+	// a raw body is read after send returns, its context lives until it is closed
+	if resp != nil && resp.RawResponse != nil && settingsOf(r.Context()).rawBody {
+		resp.RawResponse.Body = &cancelBody{ReadCloser: resp.RawResponse.Body, cancel: cancel}
+	} else {
+		cancel()
+	}
+
+	client.report(r, method, url, start, resp, err)
+	client.logAccess(r, method, start, resp, err)
+
+	result := newResult(err, resp)
+
+	if settings := settingsOf(r.Context()); settings.record != nil && r.RawRequest != nil {
+		settings.record(RecordedRequest{
+			Method: method,
+			URL:    r.RawRequest.URL.String(),
+			Header: r.RawRequest.Header.Clone(),
+			Body:   settings.requestBody,
+			Result: result,
+		})
+	}
 
-	return newResult(err, resp)
+	return result
 }
@@ -1,9 +1,22 @@
 package restclient
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -17,3 +30,839 @@ func TestURL(t *testing.T) {
 
 	println(u.String())
 }
+
+func TestErrorEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).GET("/missing", nil)
+
+	require.True(t, result.Fail())
+
+	err := result.Error()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf("GET %s/missing status code(404 Not Found)", server.URL))
+	require.NotContains(t, err.Error(), "status code(404 Not Found) ")
+}
+
+func TestWithAuthorization(t *testing.T) {
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.True(t, client.GET("/", nil, WithTokenScheme("Token", "abc")).OK())
+	require.Equal(t, "Token abc", authorization)
+
+	require.True(t, client.GET("/", nil, WithAuthorization("raw-value")).OK())
+	require.Equal(t, "raw-value", authorization)
+}
+
+func TestWithMaxRequestBytes(t *testing.T) {
+	var hits int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	body := map[string]string{"data": strings.Repeat("x", 1024)}
+
+	result := client.POST("/upload", body, WithMaxRequestBytes(512))
+
+	require.True(t, result.Fail())
+	require.Nil(t, result.Response())
+	require.Contains(t, result.Error().Error(), "request too large")
+	require.Equal(t, 0, hits)
+
+	require.True(t, client.POST("/upload", body, WithMaxRequestBytes(4096)).OK())
+	require.Equal(t, 1, hits)
+}
+
+func TestWithQueryParamsFromStruct(t *testing.T) {
+	var query url.Values
+	var body map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	page := struct {
+		Page  int `json:"page"`
+		Limit int `json:"limit"`
+	}{Page: 2, Limit: 10}
+
+	result := New(server.URL).POST("/items", map[string]string{"name": "test"}, WithQueryParamsFromStruct(page))
+
+	require.True(t, result.OK())
+	require.Equal(t, "2", query.Get("page"))
+	require.Equal(t, "10", query.Get("limit"))
+	require.Equal(t, "test", body["name"])
+}
+
+func TestWithHeaderSuccessFunc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.Header().Set("X-Error-Code", "42")
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	noErrorCode := WithHeaderSuccessFunc(func(header http.Header) bool {
+		return header.Get("X-Error-Code") == ""
+	})
+
+	require.True(t, client.GET("/ok", nil, noErrorCode).OK())
+
+	result := client.GET("/fail", nil, noErrorCode)
+
+	require.True(t, result.Fail())
+	require.Error(t, result.Error())
+	require.Contains(t, result.Error().Error(), fmt.Sprintf("GET %s/fail status code(200 OK) {} rejected by response header check", server.URL))
+}
+
+func TestWithHostHeader(t *testing.T) {
+	var host string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	require.True(t, New(server.URL).GET("/", nil, WithHostHeader("api.example.com")).OK())
+	require.Equal(t, "api.example.com", host)
+}
+
+func TestWithoutHeader(t *testing.T) {
+	var authorization []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithDefaultOptions(WithJWToken("abc")))
+
+	require.True(t, client.GET("/private", nil).OK())
+	require.True(t, client.GET("/public", nil, WithoutHeader("Authorization")).OK())
+	require.True(t, client.GET("/private", nil).OK())
+
+	require.Equal(t, []string{"Bearer abc", "", "Bearer abc"}, authorization)
+}
+
+func TestWithMethodDefaults(t *testing.T) {
+	var confirm []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		confirm = append(confirm, r.Header.Get("X-Confirm"))
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithMethodDefaults("delete", func(request *http.Request) {
+		request.Header.Set("X-Confirm", "yes")
+	}))
+
+	require.True(t, client.DELETE("/", nil).OK())
+	require.True(t, client.GET("/", nil).OK())
+	require.True(t, client.DELETE("/", nil, WithoutHeader("X-Confirm")).OK())
+
+	require.Equal(t, []string{"yes", "", ""}, confirm)
+}
+
+func TestWithErrorBodyLimit(t *testing.T) {
+	page := "<html>" + strings.Repeat("x", 4096) + "</html>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	result := client.GET("/", nil, WithErrorBodyLimit(16))
+
+	require.Contains(t, result.Error().Error(), page[:16]+"...")
+	require.NotContains(t, result.Error().Error(), page[:17])
+	require.Equal(t, page, string(result.Bytes()))
+
+	result = client.GET("/", nil)
+
+	require.Contains(t, result.Error().Error(), page[:defaultErrorBodyLimit]+"...")
+	require.Equal(t, page, string(result.Bytes()))
+
+	require.Contains(t, client.GET("/", nil, WithErrorBodyLimit(-1)).Error().Error(), page)
+}
+
+func TestWithBodyFunc(t *testing.T) {
+	var stamps []int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Timestamp int64 `json:"timestamp"`
+		}
+
+		json.NewDecoder(r.Body).Decode(&body)
+		stamps = append(stamps, body.Timestamp)
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	stamped := WithBodyFunc(func(ctx context.Context) (interface{}, error) {
+		return map[string]int64{"timestamp": time.Now().UnixNano()}, nil
+	})
+
+	require.True(t, client.POST("/signed", nil, stamped).OK())
+	require.True(t, client.POST("/signed", nil, stamped).OK())
+
+	require.Len(t, stamps, 2)
+	require.NotZero(t, stamps[0])
+	require.True(t, stamps[1] > stamps[0])
+
+	result := client.POST("/signed", nil, WithBodyFunc(func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("no nonce")
+	}))
+
+	require.Contains(t, result.Error().Error(), "no nonce")
+	require.Len(t, stamps, 2)
+}
+
+// timeoutOnce fail the first round trip with a timeout, the next go to the default transport
+type timeoutOnce struct {
+	failed bool
+}
+
+func (rt *timeoutOnce) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !rt.failed {
+		rt.failed = true
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+	}
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestWithBodyFuncPerAttempt(t *testing.T) {
+	var bodies []string
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	var produced int32
+
+	nonce := WithBodyFunc(func(ctx context.Context) (interface{}, error) {
+		return map[string]int32{"nonce": atomic.AddInt32(&produced, 1)}, nil
+	})
+
+	// a retried request gets a fresh body
+	client := New(server.URL, WithRetry(1, time.Millisecond), WithSchemeTransport("http", &timeoutOnce{}))
+
+	require.True(t, client.POST("/", nil, nonce).OK())
+	require.Equal(t, int32(2), produced)
+	require.Equal(t, []string{`{"nonce":2}`}, bodies)
+
+	// the body is produced once the request holds a concurrency slot
+	client = New(server.URL, WithMaxConcurrency(1))
+	limiter := client.(*clientImpl).limiter
+	produced = 0
+
+	go client.POST("/slow", nil)
+
+	require.Eventually(t, func() bool {
+		limiter.Lock()
+		defer limiter.Unlock()
+
+		return limiter.free == 0
+	}, time.Second, time.Millisecond)
+
+	done := make(chan Result)
+
+	go func() {
+		done <- client.POST("/", nil, nonce)
+	}()
+
+	require.Eventually(t, func() bool { return waitersOf(limiter) == 1 }, time.Second, time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&produced))
+
+	close(release)
+
+	require.True(t, (<-done).OK())
+	require.Equal(t, int32(1), produced)
+}
+
+func TestWithAccept(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json;q=1.0, application/xml;q=0.5", r.Header.Get("Accept"))
+
+		if r.URL.Path == "/xml" {
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(`<user><name>test</name></user>`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"test"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	for _, path := range []string{"/json", "/xml"} {
+		var v user
+
+		require.NoError(t, client.GET(path, nil, WithAccept("application/json", "application/xml")).Into(&v))
+		require.Equal(t, "test", v.Name)
+	}
+}
+
+func TestWithURLFunc(t *testing.T) {
+	var query url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	sign := WithURLFunc(func(u *url.URL) {
+		values := u.Query()
+		values.Set("signature", fmt.Sprintf("%x", sha256.Sum256([]byte(u.Path+"?"+values.Encode()))))
+		u.RawQuery = values.Encode()
+	})
+
+	result := New(server.URL).GET("/objects", map[string]string{"name": "a"}, sign, WithQueryParamsFromStruct(map[string]int{"expires": 60}))
+
+	require.True(t, result.OK())
+	require.Equal(t, "a", query.Get("name"))
+	require.Equal(t, "60", query.Get("expires"))
+	require.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte("/objects?expires=60&name=a"))), query.Get("signature"))
+}
+
+func TestValueMalformedJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/html" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body>502 Bad Gateway</body></html>`))
+			return
+		}
+
+		w.Write([]byte(`{"name":"test"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	var name string
+
+	result := client.GET("/html", nil)
+
+	require.True(t, result.OK())
+
+	err := result.Value("name", &name)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `content type "text/html"`)
+	require.Contains(t, err.Error(), "<html><body>502 Bad Gateway")
+	require.Nil(t, result.Values())
+
+	err = client.GET("/json", nil).Value("missing", &name)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown return value missing")
+}
+
+func TestReplay(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		requests = append(requests, fmt.Sprintf("%s %s %s %s", r.Method, r.URL.Path, r.Header.Get("Authorization"), body))
+
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).POST("/orders", map[string]int{"qty": 2}, WithAuthorization("Bearer stale"))
+
+	require.True(t, result.Fail())
+
+	result = result.Replay(WithAuthorization("Bearer fresh"))
+
+	require.True(t, result.OK())
+	require.Equal(t, []string{
+		`POST /orders Bearer stale {"qty":2}`,
+		`POST /orders Bearer fresh {"qty":2}`,
+	}, requests)
+
+	// a reader body is replayed with the bytes sent the first time
+	requests = nil
+
+	result = New(server.URL).POST("/orders", strings.NewReader("hello"), WithAuthorization("Bearer stale"))
+
+	require.True(t, result.Replay(WithAuthorization("Bearer fresh")).OK())
+	require.Equal(t, []string{
+		`POST /orders Bearer stale hello`,
+		`POST /orders Bearer fresh hello`,
+	}, requests)
+
+	// a reader which can't be rewound isn't replayed
+	result = New(server.URL).POST("/orders", ioutil.NopCloser(strings.NewReader("hello")), WithAuthorization("Bearer fresh"))
+
+	require.True(t, result.OK())
+	require.Contains(t, result.Replay().Error().Error(), "body reader can't be read again")
+	require.Len(t, requests, 3)
+}
+
+func TestSplitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	client := New(server.URL)
+
+	result, err := client.GETE(context.Background(), "/missing", nil)
+
+	require.NoError(t, err)
+	require.True(t, result.Fail())
+	require.Equal(t, http.StatusNotFound, result.Response().StatusCode())
+
+	server.Close()
+
+	result, err = client.POSTE(context.Background(), "/orders", nil)
+
+	require.Error(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, err, result.Error())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = New(server.URL).DELETEE(ctx, "/orders", nil)
+
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestBuildURL(t *testing.T) {
+	client := New("http://test.com/api/")
+
+	query := struct {
+		Page  int    `json:"page"`
+		Order string `json:"order"`
+	}{Page: 2, Order: "desc"}
+
+	u, err := client.BuildURL("/users/{id}/orders/{order}", query, WithPathParam("id", "a/b"), WithPathParam("order", "7"))
+
+	require.NoError(t, err)
+	require.Equal(t, "http://test.com/api/users/a%2Fb/orders/7?order=desc&page=2", u)
+
+	u, err = client.BuildURL("//users", nil, WithQueryParamsFromStruct(map[string]int{"limit": 10}))
+
+	require.NoError(t, err)
+	require.Equal(t, "http://test.com/api/users?limit=10", u)
+}
+
+func TestDecodeTarget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"name":"a"}`))
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).GET("/", nil)
+
+	var record struct {
+		ID int `json:"id"`
+	}
+
+	err := result.Into(record)
+	require.EqualError(t, err, "Into requires a non-nil pointer, got struct { ID int \"json:\\\"id\\\"\" }")
+
+	var nilRecord *struct{}
+
+	err = result.Into(nilRecord)
+	require.EqualError(t, err, "Into requires a non-nil pointer, got nil *struct {}")
+
+	require.EqualError(t, result.Into(nil), "Into requires a non-nil pointer, got nil")
+
+	var id int
+
+	require.EqualError(t, result.Value("id", id), "Value requires a non-nil pointer, got int")
+	require.NoError(t, result.Value("id", &id))
+	require.Equal(t, 1, id)
+
+	var tree interface{}
+
+	require.NoError(t, result.Into(&tree))
+	require.Equal(t, map[string]interface{}{"id": float64(1), "name": "a"}, tree)
+
+	values := map[string]interface{}{}
+
+	require.NoError(t, result.Into(values))
+	require.Equal(t, "a", values["name"])
+}
+
+func TestResultIs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).POST("/", nil)
+
+	require.True(t, result.Is(http.StatusConflict))
+	require.False(t, result.Is(http.StatusOK))
+
+	require.False(t, newResult(errors.New("no response"), nil).Is(http.StatusConflict))
+	require.False(t, New("http://"+refusedAddr(t)).GET("/", nil).Is(0))
+}
+
+func TestDecodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/html" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html>oops</html>`))
+			return
+		}
+
+		w.Write([]byte(`{"id":"not a number"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	var id int
+
+	err := client.GET("/", nil).Value("id", &id)
+
+	var decodeErr *DecodeError
+
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "id", decodeErr.Key)
+	require.Equal(t, `"not a number"`, string(decodeErr.Body))
+	require.Error(t, decodeErr.Cause)
+	require.True(t, strings.HasPrefix(err.Error(), "unmarshal result(id) err "))
+
+	var record struct {
+		ID int `json:"id"`
+	}
+
+	err = client.GET("/", nil).Into(&record)
+
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "", decodeErr.Key)
+	require.Equal(t, `{"id":"not a number"}`, string(decodeErr.Body))
+
+	err = client.GET("/html", nil).Value("id", &id)
+
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, `<html>oops</html>`, string(decodeErr.Body))
+	require.Contains(t, err.Error(), `decode response(content type "text/html") err`)
+}
+
+func TestEncodeRequestBodyError(t *testing.T) {
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	type badBody struct {
+		Events chan int `json:"events"`
+	}
+
+	result := New(server.URL).POST("/", badBody{Events: make(chan int)})
+
+	require.Error(t, result.Error())
+	require.Contains(t, result.Error().Error(), "failed to encode request body(restclient.badBody)")
+	require.Equal(t, 0, calls)
+}
+
+func TestResultAny(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/array":
+			w.Write([]byte(`[1,"a",{"b":true}]`))
+		case "/scalar":
+			w.Write([]byte(`42`))
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	v, err := client.GET("/array", nil).Any()
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{float64(1), "a", map[string]interface{}{"b": true}}, v)
+
+	v, err = client.GET("/scalar", nil).Any()
+	require.NoError(t, err)
+	require.Equal(t, float64(42), v)
+
+	_, err = client.GET("/invalid", nil).Any()
+	require.Error(t, err)
+}
+
+func TestWithReferer(t *testing.T) {
+	var referer string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		referer = r.Header.Get("Referer")
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.True(t, client.POST("/", nil, WithReferer("https://app.test.com/checkout")).OK())
+	require.Equal(t, "https://app.test.com/checkout", referer)
+
+	referer = ""
+
+	for _, invalid := range []string{"checkout", "/checkout", "ftp://test.com", "https://", "http://%zz"} {
+		result := client.POST("/", nil, WithReferer(invalid))
+
+		require.Error(t, result.Error())
+		require.Contains(t, result.Error().Error(), "invalid referer")
+	}
+
+	require.Equal(t, "", referer)
+}
+
+func TestPath(t *testing.T) {
+	require.Equal(t, "/users/42/orders/a%2Fb", Path("users", 42, "orders", "a/b"))
+	require.Equal(t, "/files/%2E%2E/%2E/x%3Fy=1%23z", Path("files", "..", ".", "x?y=1#z"))
+	require.Equal(t, "/a%20b/%25", Path("a b", "%"))
+	require.Equal(t, "", Path())
+
+	var rawPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawPath = r.URL.EscapedPath()
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	require.True(t, New(server.URL).GET(Path("users", "../admin"), nil).OK())
+	require.Equal(t, "/users/..%2Fadmin", rawPath)
+}
+
+func TestPOSTForm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		require.NoError(t, r.ParseForm())
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"form": r.PostForm, "query": r.URL.RawQuery})
+	}))
+
+	defer server.Close()
+
+	form := url.Values{"grant_type": {"password"}, "scope": {"read", "write"}, "note": {"a&b=c"}}
+
+	var reply struct {
+		Form  url.Values `json:"form"`
+		Query string     `json:"query"`
+	}
+
+	require.NoError(t, New(server.URL).POSTForm("/token", form).Into(&reply))
+	require.Equal(t, form, reply.Form)
+	require.Equal(t, "", reply.Query)
+}
+
+func TestResultExpect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/html" {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	result := client.POST("/", nil)
+
+	require.NoError(t, result.Expect(http.StatusCreated, "application/json"))
+	require.NoError(t, result.Expect(http.StatusCreated, ""))
+	require.Error(t, result.Expect(http.StatusOK, "application/json"))
+	require.Error(t, result.Expect(http.StatusCreated, "application/xml"))
+
+	err := client.GET("/html", nil).Expect(http.StatusOK, "application/json")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `expect status 200 content type "application/json", got status code(404 Not Found) content type "text/html"`)
+
+	require.Error(t, New("http://"+refusedAddr(t)).GET("/", nil).Expect(http.StatusOK, ""))
+}
+
+func TestResultValueBytes(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 'h', 'i'}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"std":     base64.StdEncoding.EncodeToString(data),
+			"url":     base64.URLEncoding.EncodeToString(data),
+			"raw_url": base64.RawURLEncoding.EncodeToString(data),
+			"invalid": "not base64!",
+			"number":  1,
+		})
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).GET("/", nil)
+
+	for _, key := range []string{"std", "url", "raw_url"} {
+		decoded, err := result.ValueBytes(key)
+
+		require.NoError(t, err)
+		require.Equal(t, data, decoded)
+	}
+
+	for _, key := range []string{"invalid", "number", "missing"} {
+		_, err := result.ValueBytes(key)
+		require.Error(t, err)
+	}
+}
+
+func TestResultScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":7,"name":"bob","tags":["a","b"],"meta":{"ignored":true}}`))
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL).GET("/", nil)
+
+	var id int
+	var name string
+	var tags []string
+
+	require.NoError(t, result.Scan(ScanPair{"id", &id}, ScanPair{"name", &name}, ScanPair{"tags", &tags}))
+	require.Equal(t, 7, id)
+	require.Equal(t, "bob", name)
+	require.Equal(t, []string{"a", "b"}, tags)
+
+	name = ""
+
+	err := result.Scan(ScanPair{"id", &name}, ScanPair{"missing", &id}, ScanPair{"name", &name})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 of 3 keys failed")
+	require.Contains(t, err.Error(), "\nid: ")
+	require.Contains(t, err.Error(), "\nmissing: ")
+	require.Equal(t, "bob", name)
+
+	var scanErr *ScanError
+
+	require.True(t, errors.As(err, &scanErr))
+	require.Equal(t, "id", scanErr.Failed[0].Key)
+	require.Equal(t, "missing", scanErr.Failed[1].Key)
+
+	var decodeErr *DecodeError
+
+	require.True(t, errors.As(err, &decodeErr))
+	require.Equal(t, "id", decodeErr.Key)
+}
+
+func TestRequestBodyContentType(t *testing.T) {
+	var contentType string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	body := map[string]int{"id": 1}
+
+	vendorType := func(request *http.Request) {
+		request.Header.Set("Content-Type", "application/vnd.api+json")
+	}
+
+	require.True(t, New(server.URL).POST("/", body).OK())
+	require.Equal(t, "application/json; charset=utf-8", contentType)
+
+	require.True(t, New(server.URL).POST("/", body, vendorType).OK())
+	require.Equal(t, "application/vnd.api+json", contentType)
+
+	require.True(t, New(server.URL, WithKeyNaming(SnakeCase)).POST("/", body).OK())
+	require.Equal(t, "application/json; charset=utf-8", contentType)
+
+	require.True(t, New(server.URL, WithKeyNaming(SnakeCase)).POST("/", body, vendorType).OK())
+	require.Equal(t, "application/vnd.api+json", contentType)
+}
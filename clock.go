@@ -0,0 +1,26 @@
+package restclient
+
+import "time"
+
+// clock source of time for the retry, polling and latency logic, replaced in tests
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// withClock replace the client clock, for tests only
+func withClock(c clock) ClientOption {
+	return func(client *clientImpl) {
+		client.clock = c
+	}
+}
@@ -0,0 +1,90 @@
+package restclient
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advance the time instantly on After and record the waits
+type fakeClock struct {
+	sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	c.now = c.now.Add(d)
+	c.waits = append(c.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+
+	return ch
+}
+
+func (c *fakeClock) Waits() []time.Duration {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]time.Duration(nil), c.waits...)
+}
+
+func TestFakeClockRetryWait(t *testing.T) {
+	clock := newFakeClock()
+
+	client := New("http://"+refusedAddr(t), WithRetry(3, time.Hour), withClock(clock))
+
+	start := time.Now()
+
+	require.Error(t, client.GET("/", nil).Error())
+
+	require.True(t, time.Since(start) < time.Second)
+	require.Equal(t, []time.Duration{time.Hour, time.Hour, time.Hour}, clock.Waits())
+}
+
+func TestFakeClockPollTimeout(t *testing.T) {
+	server, polls := newJobServer(1000)
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	_, err := New(server.URL, withClock(clock)).PollUntil("/job", nil, jobDone, time.Minute, 10*time.Minute)
+
+	require.Error(t, err)
+	require.Equal(t, int32(10), *polls)
+	require.Len(t, clock.Waits(), 9)
+}
+
+func TestFakeClockSlowRequest(t *testing.T) {
+	clock := newFakeClock()
+
+	var slow []SlowRequestInfo
+
+	client := New("http://"+refusedAddr(t), WithRetry(1, time.Minute), withClock(clock),
+		WithSlowRequestThreshold(30*time.Second, func(info SlowRequestInfo) {
+			slow = append(slow, info)
+		}))
+
+	client.GET("/", nil)
+
+	require.Len(t, slow, 1)
+	require.Equal(t, time.Minute, slow[0].Elapsed)
+}
@@ -0,0 +1,132 @@
+package restclient
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty"
+)
+
+// Codec encode and decode bodies of one content type
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// WithMsgPackCodec enable msgpack bodies with codec, the codec lives outside of
+// this package to keep the msgpack dependency optional, see package restclient/msgpack
+func WithMsgPackCodec(codec Codec) ClientOption {
+	return func(client *clientImpl) {
+		client.msgpack = codec
+	}
+}
+
+// WithMsgPackBody encode the request body as msgpack and ask for a msgpack response,
+// the client must be created with WithMsgPackCodec
+func WithMsgPackBody() Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+
+		if settings.msgpack == nil {
+			if settings.err == nil {
+				settings.err = fmt.Errorf("msgpack codec not configured, create the client with WithMsgPackCodec")
+			}
+
+			return
+		}
+
+		settings.msgpackBody = true
+
+		request.Header.Set("Accept", settings.msgpack.ContentType())
+	}
+}
+
+// setBody set request body, encoded by the codec selected by the request options, else
+// marshalled as json here. raw bodies and bodies with an xml Content-Type are left to resty
+func (client *clientImpl) setBody(r *resty.Request, request interface{}) error {
+	settings := settingsOf(r.Context())
+
+	if !settings.msgpackBody {
+		contentType := r.Header.Get("Content-Type")
+
+		// raw bodies are sent as-is, resty marshals xml bodies itself
+		if isRawBody(request) || strings.Contains(contentType, "xml") {
+			r.SetBody(request)
+			return nil
+		}
+
+		// marshalled here rather than by resty, so the error names the body
+		data, err := marshalJSON(request, settings.naming)
+
+		if err != nil {
+			return fmt.Errorf("failed to encode request body(%T) err %s", request, err)
+		}
+
+		// the resty default, a Content-Type set by the caller is kept
+		if contentType == "" {
+			r.SetHeader("Content-Type", "application/json; charset=utf-8")
+		}
+
+		r.SetBody(data)
+
+		return nil
+	}
+
+	data, err := settings.msgpack.Marshal(request)
+
+	if err != nil {
+		return fmt.Errorf("marshal msgpack body err %s", err)
+	}
+
+	r.SetHeader("Content-Type", settings.msgpack.ContentType()).SetBody(data)
+
+	return nil
+}
+
+// produceBody set the body produced by WithBodyFunc, before every attempt of the request
+func (client *clientImpl) produceBody(r *resty.Request) error {
+	body, err := settingsOf(r.Context()).bodyFunc(r.Context())
+
+	if err != nil {
+		return fmt.Errorf("produce request body err %s", err)
+	}
+
+	return client.setBody(r, body)
+}
+
+// MsgPack unmarshal the msgpack response body into v
+func (result *resultImpl) MsgPack(v interface{}) error {
+	if result.settings.msgpack == nil {
+		return fmt.Errorf("msgpack codec not configured, create the client with WithMsgPackCodec")
+	}
+
+	if result.resp == nil {
+		return fmt.Errorf("unmarshal msgpack result err %s", result.Error())
+	}
+
+	if err := result.settings.msgpack.Unmarshal(result.resp.Body(), v); err != nil {
+		return fmt.Errorf("unmarshal msgpack result err %s", err)
+	}
+
+	return nil
+}
+
+// unmarshaler pick the body decoder by the response Content-Type
+func (result *resultImpl) unmarshaler() func(data []byte, v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(result.resp.Header().Get("Content-Type"))
+
+	switch {
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return xml.Unmarshal
+	case result.settings.msgpack != nil && mediaType == result.settings.msgpack.ContentType():
+		return result.settings.msgpack.Unmarshal
+	default:
+		return func(data []byte, v interface{}) error {
+			return unmarshalJSON(data, v, result.settings.naming)
+		}
+	}
+}
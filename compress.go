@@ -0,0 +1,103 @@
+package restclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// WithAutoGzip gzip the request body when its serialized size exceeds minBytes,
+// smaller bodies aren't worth the cpu and are sent as-is. 0 compresses any non-empty body
+func WithAutoGzip(minBytes int) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).autoGzip = &minBytes
+	}
+}
+
+// gzipBody replace the body of request with its gzip encoding
+func gzipBody(request *http.Request) error {
+	body, err := request.GetBody()
+
+	if err != nil {
+		return fmt.Errorf("gzip request body err %s", err)
+	}
+
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+
+	if err != nil {
+		return fmt.Errorf("gzip request body err %s", err)
+	}
+
+	var buff bytes.Buffer
+
+	writer := gzip.NewWriter(&buff)
+
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("gzip request body err %s", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("gzip request body err %s", err)
+	}
+
+	compressed := buff.Bytes()
+
+	request.Body = ioutil.NopCloser(bytes.NewReader(compressed))
+	request.ContentLength = int64(len(compressed))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(compressed)), nil
+	}
+
+	request.Header.Set("Content-Encoding", "gzip")
+
+	return nil
+}
+
+// WithLenientDecompression take a response body labelled gzip which does not start with the
+// gzip magic bytes as plain text instead of failing, for servers which mislabel their bodies
+func WithLenientDecompression() ClientOption {
+	return func(client *clientImpl) {
+		client.transport.lenientGzip = true
+	}
+}
+
+type gunzipBody struct {
+	io.Reader
+	io.Closer
+}
+
+// lenientGunzip decompress the gzip labelled body of resp, or keep it as-is if it isn't gzip
+func lenientGunzip(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	var body io.Reader = reader
+
+	if magic, _ := reader.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+
+		if err != nil {
+			return fmt.Errorf("gunzip response body err %s", err)
+		}
+
+		body = gz
+	}
+
+	resp.Body = &gunzipBody{Reader: body, Closer: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return nil
+}
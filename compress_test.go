@@ -0,0 +1,154 @@
+package restclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAutoGzip(t *testing.T) {
+	var encoding string
+	var body map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+
+		var reader io.Reader = r.Body
+
+		if encoding == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			reader = gz
+		}
+
+		body = nil
+		require.NoError(t, json.NewDecoder(reader).Decode(&body))
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	small := map[string]string{"name": "test"}
+
+	require.True(t, client.POST("/", small, WithAutoGzip(1024)).OK())
+	require.Equal(t, "", encoding)
+	require.Equal(t, small, body)
+
+	large := map[string]string{"name": strings.Repeat("x", 4096)}
+
+	require.True(t, client.POST("/", large, WithAutoGzip(1024)).OK())
+	require.Equal(t, "gzip", encoding)
+	require.Equal(t, large, body)
+
+	require.True(t, client.POST("/", small, WithAutoGzip(0)).OK())
+	require.Equal(t, "gzip", encoding)
+	require.Equal(t, small, body)
+
+	require.True(t, client.POST("/", small).OK())
+	require.Equal(t, "", encoding)
+}
+
+func TestMultiMemberGzipResponse(t *testing.T) {
+	var body bytes.Buffer
+
+	for _, member := range []string{`{"items":[1,`, `2,`, `3]}`} {
+		writer := gzip.NewWriter(&body)
+		writer.Write([]byte(member))
+		writer.Close()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body.Bytes())
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	// decompressed by the transport, and by resty when the caller asks for gzip itself
+	acceptGzip := func(request *http.Request) {
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	for _, option := range []Option{WithAccept("application/json"), acceptGzip} {
+		result := client.GET("/", nil, option)
+
+		require.True(t, result.OK())
+		require.Equal(t, `{"items":[1,2,3]}`, string(result.Bytes()))
+	}
+}
+
+func TestWithDisableCompression(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(`{"id":1}`))
+	writer.Close()
+
+	var accept []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = append(accept, r.Header.Get("Accept-Encoding"))
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compressed.Bytes())
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL, WithDisableCompression()).GET("/", nil)
+
+	require.True(t, result.OK())
+	require.Equal(t, compressed.Bytes(), result.Bytes())
+	require.Equal(t, "gzip", result.Response().Header().Get("Content-Encoding"))
+
+	require.Equal(t, `{"id":1}`, string(New(server.URL).GET("/", nil).Bytes()))
+
+	require.Equal(t, []string{"", "gzip"}, accept)
+}
+
+func TestWithLenientDecompression(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(`{"id":2}`))
+	writer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+
+		if r.URL.Path == "/plain" {
+			w.Write([]byte(`{"id":1}`))
+			return
+		}
+
+		w.Write(compressed.Bytes())
+	}))
+
+	defer server.Close()
+
+	require.Error(t, New(server.URL).GET("/plain", nil).Error())
+
+	client := New(server.URL, WithLenientDecompression())
+
+	result := client.GET("/plain", nil)
+
+	require.True(t, result.OK())
+	require.Equal(t, `{"id":1}`, string(result.Bytes()))
+
+	result = client.GET("/gzip", nil)
+
+	require.True(t, result.OK())
+	require.Equal(t, `{"id":2}`, string(result.Bytes()))
+}
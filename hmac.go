@@ -0,0 +1,92 @@
+package restclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// DefaultSignatureHeader the header VerifyHMAC reads the signature from by default
+const DefaultSignatureHeader = "X-Signature"
+
+type hmacVerify struct {
+	header string
+	prefix string
+	hash   func() hash.Hash
+	decode func(s string) ([]byte, error)
+}
+
+// VerifyOption configure VerifyHMAC
+type VerifyOption func(verify *hmacVerify)
+
+// WithSignatureHeader read the signature from header instead of X-Signature
+func WithSignatureHeader(header string) VerifyOption {
+	return func(verify *hmacVerify) {
+		verify.header = header
+	}
+}
+
+// WithSignaturePrefix strip prefix from the header value, e.g. "sha256="
+func WithSignaturePrefix(prefix string) VerifyOption {
+	return func(verify *hmacVerify) {
+		verify.prefix = prefix
+	}
+}
+
+// WithSignatureHash compute the mac with h instead of sha256
+func WithSignatureHash(h func() hash.Hash) VerifyOption {
+	return func(verify *hmacVerify) {
+		verify.hash = h
+	}
+}
+
+// WithBase64Signature decode the signature as std base64 instead of hex
+func WithBase64Signature() VerifyOption {
+	return func(verify *hmacVerify) {
+		verify.decode = base64.StdEncoding.DecodeString
+	}
+}
+
+// VerifyHMAC check an inbound webhook signature, the hmac of body keyed with secret
+// is compared in constant time with the signature in header
+func VerifyHMAC(header http.Header, body []byte, secret string, options ...VerifyOption) error {
+	verify := &hmacVerify{
+		header: DefaultSignatureHeader,
+		hash:   sha256.New,
+		decode: hex.DecodeString,
+	}
+
+	for _, option := range options {
+		option(verify)
+	}
+
+	value := header.Get(verify.header)
+
+	if value == "" {
+		return fmt.Errorf("verify hmac: missing %s header", verify.header)
+	}
+
+	if !strings.HasPrefix(value, verify.prefix) {
+		return fmt.Errorf("verify hmac: %s header must start with %q", verify.header, verify.prefix)
+	}
+
+	signature, err := verify.decode(strings.TrimPrefix(value, verify.prefix))
+
+	if err != nil {
+		return fmt.Errorf("verify hmac: decode %s header err %s", verify.header, err)
+	}
+
+	mac := hmac.New(verify.hash, []byte(secret))
+	mac.Write(body)
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return fmt.Errorf("verify hmac: signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package restclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyHMAC(t *testing.T) {
+	body := []byte(`{"event":"paid"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	options := []VerifyOption{WithSignatureHeader("X-Hub-Signature-256"), WithSignaturePrefix("sha256=")}
+
+	require.NoError(t, VerifyHMAC(header, body, "secret", options...))
+	require.Error(t, VerifyHMAC(header, []byte(`{"event":"refunded"}`), "secret", options...))
+	require.Error(t, VerifyHMAC(header, body, "other", options...))
+	require.Error(t, VerifyHMAC(header, body, "secret"))
+}
+
+func TestVerifyHMACBase64(t *testing.T) {
+	body := []byte("payload")
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(body)
+
+	header := http.Header{}
+	header.Set(DefaultSignatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	require.NoError(t, VerifyHMAC(header, body, "secret", WithSignatureHash(sha1.New), WithBase64Signature()))
+	require.Error(t, VerifyHMAC(header, body, "secret", WithBase64Signature()))
+}
@@ -0,0 +1,62 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONPathEngine evaluate a JSONPath expression against a decoded json document,
+// returning the matched nodes
+type JSONPathEngine interface {
+	Get(expr string, document interface{}) ([]interface{}, error)
+}
+
+// WithJSONPath enable Result.JSONPath with engine, the engine lives outside of this
+// package to keep the dependency optional, see package restclient/jsonpath
+func WithJSONPath(engine JSONPathEngine) ClientOption {
+	return func(client *clientImpl) {
+		client.jsonpath = engine
+	}
+}
+
+// JSONPath evaluate expr against the response body and unmarshal the matched nodes into v,
+// a single matched node is unmarshalled as is when it fits v, e.g. "$.total" into an int
+func (result *resultImpl) JSONPath(expr string, v interface{}) error {
+	if result.settings.jsonpath == nil {
+		return fmt.Errorf("jsonpath engine not configured, create the client with WithJSONPath")
+	}
+
+	if result.resp == nil {
+		return fmt.Errorf("jsonpath result(%s) err %s", expr, result.Error())
+	}
+
+	var document interface{}
+
+	if err := json.Unmarshal(result.resp.Body(), &document); err != nil {
+		return fmt.Errorf("unmarshal result err %s\n%s", err, string(result.resp.Body()))
+	}
+
+	nodes, err := result.settings.jsonpath.Get(expr, document)
+
+	if err != nil {
+		return fmt.Errorf("jsonpath result(%s) err %s\n%s", expr, err, string(result.resp.Body()))
+	}
+
+	if len(nodes) == 1 {
+		if buff, err := json.Marshal(nodes[0]); err == nil && json.Unmarshal(buff, v) == nil {
+			return nil
+		}
+	}
+
+	buff, err := json.Marshal(nodes)
+
+	if err != nil {
+		return fmt.Errorf("jsonpath result(%s) err %s", expr, err)
+	}
+
+	if err := json.Unmarshal(buff, v); err != nil {
+		return fmt.Errorf("unmarshal result(%s) err %s\n%s", expr, err, string(buff))
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+// Package jsonpath provides the JSONPath engine for restclient.WithJSONPath
+package jsonpath
+
+import (
+	"github.com/dynamicgo/restclient"
+	"github.com/ohler55/ojg/jp"
+)
+
+type engineImpl struct{}
+
+// New create JSONPath engine supporting wildcards, filters and array slices
+func New() restclient.JSONPathEngine {
+	return &engineImpl{}
+}
+
+func (engine *engineImpl) Get(expr string, document interface{}) ([]interface{}, error) {
+	x, err := jp.ParseString(expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return x.Get(document), nil
+}
@@ -0,0 +1,55 @@
+package jsonpath
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dynamicgo/restclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[{"id":1,"active":true},{"id":2,"active":false},{"id":3,"active":true}]}`))
+	}))
+
+	defer server.Close()
+
+	result := restclient.New(server.URL, restclient.WithJSONPath(New())).GET("/items", nil)
+
+	require.True(t, result.OK())
+
+	var ids []int
+
+	require.NoError(t, result.JSONPath("$.items[*].id", &ids))
+	require.Equal(t, []int{1, 2, 3}, ids)
+
+	require.NoError(t, result.JSONPath("$.items[?(@.active==true)].id", &ids))
+	require.Equal(t, []int{1, 3}, ids)
+
+	require.NoError(t, result.JSONPath("$.items[0:2].id", &ids))
+	require.Equal(t, []int{1, 2}, ids)
+
+	var id int
+
+	require.NoError(t, result.JSONPath("$.items[1].id", &id))
+	require.Equal(t, 2, id)
+
+	require.Error(t, result.JSONPath("$.items[", &ids))
+}
+
+func TestJSONPathNotConfigured(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	var v interface{}
+
+	err := restclient.New(server.URL).GET("/", nil).JSONPath("$.id", &v)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "WithJSONPath")
+}
@@ -0,0 +1,110 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// prioritySemaphore counting semaphore handing free slots to the highest priority waiter,
+// waiters of the same priority are served in arrival order
+type prioritySemaphore struct {
+	sync.Mutex
+	free    int
+	waiters []*slotWaiter
+}
+
+type slotWaiter struct {
+	priority int
+	ready    chan struct{}
+}
+
+func newPrioritySemaphore(n int) *prioritySemaphore {
+	return &prioritySemaphore{free: n}
+}
+
+func (sem *prioritySemaphore) acquire(ctx context.Context, priority int) error {
+	sem.Lock()
+
+	if sem.free > 0 && len(sem.waiters) == 0 {
+		sem.free--
+		sem.Unlock()
+
+		return nil
+	}
+
+	waiter := &slotWaiter{priority: priority, ready: make(chan struct{})}
+	sem.waiters = append(sem.waiters, waiter)
+
+	sem.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	sem.Lock()
+	defer sem.Unlock()
+
+	for i, w := range sem.waiters {
+		if w == waiter {
+			sem.waiters = append(sem.waiters[:i], sem.waiters[i+1:]...)
+			return ctx.Err()
+		}
+	}
+
+	// the slot was handed over while ctx was done, pass it on
+	sem.releaseLocked()
+
+	return ctx.Err()
+}
+
+func (sem *prioritySemaphore) release() {
+	sem.Lock()
+	defer sem.Unlock()
+
+	sem.releaseLocked()
+}
+
+func (sem *prioritySemaphore) releaseLocked() {
+	if len(sem.waiters) == 0 {
+		sem.free++
+		return
+	}
+
+	next := 0
+
+	for i, w := range sem.waiters {
+		if w.priority > sem.waiters[next].priority {
+			next = i
+		}
+	}
+
+	waiter := sem.waiters[next]
+	sem.waiters = append(sem.waiters[:next], sem.waiters[next+1:]...)
+
+	close(waiter.ready)
+}
+
+// WithMaxConcurrency allow at most n requests of the client in flight, the others wait for
+// a slot in priority order, see WithPriority. the slot is held during retries. n <= 0 means
+// no limit
+func WithMaxConcurrency(n int) ClientOption {
+	return func(client *clientImpl) {
+		if n <= 0 {
+			client.limiter = nil
+			return
+		}
+
+		client.limiter = newPrioritySemaphore(n)
+	}
+}
+
+// WithPriority the priority of the request waiting for a WithMaxConcurrency slot, higher
+// levels go first, the default is 0
+func WithPriority(level int) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).priority = level
+	}
+}
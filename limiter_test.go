@@ -0,0 +1,104 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func waitersOf(sem *prioritySemaphore) int {
+	sem.Lock()
+	defer sem.Unlock()
+
+	return len(sem.waiters)
+}
+
+func TestWithPriority(t *testing.T) {
+	held := make(chan struct{})
+	hold := make(chan struct{})
+
+	var mutex sync.Mutex
+	var order []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hold" {
+			close(held)
+			<-hold
+		} else {
+			mutex.Lock()
+			order = append(order, r.URL.Query().Get("name"))
+			mutex.Unlock()
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithMaxConcurrency(1))
+	limiter := client.(*clientImpl).limiter
+
+	var wg sync.WaitGroup
+
+	send := func(path string, name string, options ...Option) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			require.True(t, client.GET(path, map[string]string{"name": name}, options...).OK())
+		}()
+	}
+
+	send("/hold", "hold")
+	<-held
+
+	send("/batch", "low1", WithPriority(0))
+	require.Eventually(t, func() bool { return waitersOf(limiter) == 1 }, time.Second, time.Millisecond)
+
+	send("/batch", "low2")
+	require.Eventually(t, func() bool { return waitersOf(limiter) == 2 }, time.Second, time.Millisecond)
+
+	send("/interactive", "high", WithPriority(10))
+	require.Eventually(t, func() bool { return waitersOf(limiter) == 3 }, time.Second, time.Millisecond)
+
+	close(hold)
+	wg.Wait()
+
+	require.Equal(t, []string{"high", "low1", "low2"}, order)
+}
+
+func TestPrioritySemaphoreContext(t *testing.T) {
+	sem := newPrioritySemaphore(1)
+
+	require.NoError(t, sem.acquire(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, sem.acquire(ctx, 0))
+	require.Equal(t, 0, waitersOf(sem))
+
+	sem.release()
+
+	require.NoError(t, sem.acquire(context.Background(), 0))
+}
+
+func TestWithMaxConcurrencyUnlimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	for _, n := range []int{0, -1} {
+		client := New(server.URL, WithMaxConcurrency(n))
+
+		require.Nil(t, client.(*clientImpl).limiter)
+		require.True(t, client.GET("/", nil).OK())
+	}
+}
@@ -0,0 +1,59 @@
+package restclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty"
+)
+
+// RequestMetric describe a finished request for the metrics callback
+type RequestMetric struct {
+	Method     string
+	URL        string
+	StatusCode int           // 0 if no response was received
+	Elapsed    time.Duration // including retries
+	Err        error         // transport error, nil if a response was received
+	Labels     map[string]string
+}
+
+// WithMetrics call fn after every request of the client, label requests with WithMetricLabel
+// to group them by operation instead of by url
+func WithMetrics(fn func(metric RequestMetric)) ClientOption {
+	return func(client *clientImpl) {
+		client.metrics = fn
+	}
+}
+
+// WithMetricLabel attach the label key=value to the metric of the request, e.g. an operation name
+func WithMetricLabel(key, value string) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+
+		if settings.metricLabels == nil {
+			settings.metricLabels = make(map[string]string)
+		}
+
+		settings.metricLabels[key] = value
+	}
+}
+
+func (client *clientImpl) report(r *resty.Request, method string, url string, start time.Time, resp *resty.Response, err error) {
+	if client.metrics == nil {
+		return
+	}
+
+	metric := RequestMetric{
+		Method:  method,
+		URL:     url,
+		Elapsed: client.clock.Now().Sub(start),
+		Err:     err,
+		Labels:  settingsOf(r.Context()).metricLabels,
+	}
+
+	if resp != nil && resp.RawResponse != nil {
+		metric.StatusCode = resp.StatusCode()
+	}
+
+	client.metrics(metric)
+}
@@ -0,0 +1,33 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetricLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	var metrics []RequestMetric
+
+	client := New(server.URL, WithMetrics(func(metric RequestMetric) {
+		metrics = append(metrics, metric)
+	}))
+
+	require.True(t, client.GET("/users/42", nil, WithMetricLabel("operation", "get_user")).OK())
+	require.True(t, client.GET("/users", nil).OK())
+
+	require.Len(t, metrics, 2)
+	require.Equal(t, map[string]string{"operation": "get_user"}, metrics[0].Labels)
+	require.Equal(t, http.MethodGet, metrics[0].Method)
+	require.Equal(t, server.URL+"/users/42", metrics[0].URL)
+	require.Equal(t, http.StatusOK, metrics[0].StatusCode)
+	require.Nil(t, metrics[1].Labels)
+}
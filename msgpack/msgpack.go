@@ -0,0 +1,29 @@
+// Package msgpack provides the msgpack codec for restclient.WithMsgPackCodec
+package msgpack
+
+import (
+	"github.com/dynamicgo/restclient"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType msgpack content type
+const ContentType = "application/msgpack"
+
+type codecImpl struct{}
+
+// New create msgpack codec
+func New() restclient.Codec {
+	return &codecImpl{}
+}
+
+func (codec *codecImpl) ContentType() string {
+	return ContentType
+}
+
+func (codec *codecImpl) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (codec *codecImpl) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
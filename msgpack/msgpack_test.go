@@ -0,0 +1,57 @@
+package msgpack
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dynamicgo/restclient"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type item struct {
+	Name  string `msgpack:"name"`
+	Count int    `msgpack:"count"`
+}
+
+func TestMsgPackRoundtrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, ContentType, r.Header.Get("Content-Type"))
+		require.Equal(t, ContentType, r.Header.Get("Accept"))
+
+		data, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var v item
+		require.NoError(t, msgpack.Unmarshal(data, &v))
+
+		v.Count++
+
+		data, err = msgpack.Marshal(&v)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(data)
+	}))
+
+	defer server.Close()
+
+	client := restclient.New(server.URL, restclient.WithMsgPackCodec(New()))
+
+	result := client.POST("/items", &item{Name: "test", Count: 1}, restclient.WithMsgPackBody())
+
+	require.True(t, result.OK())
+
+	var v item
+	require.NoError(t, result.MsgPack(&v))
+	require.Equal(t, item{Name: "test", Count: 2}, v)
+}
+
+func TestMsgPackNotConfigured(t *testing.T) {
+	result := restclient.New("http://127.0.0.1").POST("/items", &item{}, restclient.WithMsgPackBody())
+
+	require.True(t, result.Fail())
+	require.Contains(t, result.Error().Error(), "WithMsgPackCodec")
+}
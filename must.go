@@ -0,0 +1,34 @@
+package restclient
+
+import "fmt"
+
+// Must panic when r failed, the panic carries the status and body of the response.
+// it's meant for scripts and tests, services should check Result.Error instead
+func Must(r Result) Result {
+	if r.Fail() {
+		panic(failure(r))
+	}
+
+	return r
+}
+
+// MustInto unmarshal the response body into v, it panics when the request failed or
+// the body can't be decoded. like Must it's meant for scripts and tests only
+func (result *resultImpl) MustInto(v interface{}) {
+	Must(result)
+
+	if err := result.Into(v); err != nil {
+		panic(err)
+	}
+}
+
+// failure describe a failed result with the full context
+func failure(r Result) string {
+	resp := r.Response()
+
+	if resp == nil {
+		return fmt.Sprintf("request failed: %s", r.Error())
+	}
+
+	return fmt.Sprintf("%s %s failed: status %s: %s\n%s", resp.Request.Method, resp.Request.URL, resp.Status(), r.Error(), string(resp.Body()))
+}
@@ -0,0 +1,44 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMust(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":10,"msg":"invalid name"}`))
+			return
+		}
+
+		w.Write([]byte(`{"name":"test"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	var v struct {
+		Name string `json:"name"`
+	}
+
+	Must(client.GET("/ok", nil)).MustInto(&v)
+	require.Equal(t, "test", v.Name)
+
+	require.Panics(t, func() { Must(client.GET("/fail", nil)) })
+
+	defer func() {
+		r := recover()
+
+		require.NotNil(t, r)
+		require.Contains(t, r, "400 Bad Request")
+		require.Contains(t, r, `{"code":10,"msg":"invalid name"}`)
+	}()
+
+	client.GET("/fail", nil).MustInto(&v)
+}
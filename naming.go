@@ -0,0 +1,389 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// KeyNaming json key convention applied to struct fields without json tags
+type KeyNaming int
+
+// key naming conventions
+const (
+	KeepKeys  KeyNaming = iota // use field names and struct tags as-is
+	SnakeCase                  // UserID => user_id
+	CamelCase                  // UserID => userID
+)
+
+// WithKeyNaming convert the untagged struct field names of request bodies and query params
+// to naming, and match response keys in that convention back to the untagged fields.
+// tagged fields and map keys are left as-is, map and interface{} response targets get the
+// keys as sent. WithRequestKeyNaming overrides it per request
+func WithKeyNaming(naming KeyNaming) ClientOption {
+	return func(client *clientImpl) {
+		client.naming = naming
+	}
+}
+
+// WithRequestKeyNaming override the client key naming for this request
+func WithRequestKeyNaming(naming KeyNaming) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).naming = naming
+	}
+}
+
+// marshalJSON marshal v converting the untagged struct field names to naming
+func marshalJSON(v interface{}, naming KeyNaming) ([]byte, error) {
+	data, err := json.Marshal(v)
+
+	if err != nil || naming == KeepKeys {
+		return data, err
+	}
+
+	tree, err := decodeTree(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(renameFields(tree, reflect.ValueOf(v), naming))
+}
+
+// unmarshalJSON unmarshal data into v, with a naming the keys of struct targets are matched
+// to the untagged fields as well, map and interface{} targets get the keys as-is
+func unmarshalJSON(data []byte, v interface{}, naming KeyNaming) error {
+	if naming == KeepKeys {
+		return json.Unmarshal(data, v)
+	}
+
+	tree, err := decodeTree(data)
+
+	if err != nil {
+		return json.Unmarshal(data, v)
+	}
+
+	if data, err = json.Marshal(matchFields(tree, reflect.TypeOf(v))); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// isRawBody bodies resty sends verbatim instead of marshalling them
+func isRawBody(v interface{}) bool {
+	switch v.(type) {
+	case nil, string, []byte, io.Reader:
+		return true
+	}
+
+	return false
+}
+
+func decodeTree(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree interface{}
+
+	err := decoder.Decode(&tree)
+
+	return tree, err
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// jsonField a struct field as encoding/json sees it, untagged when the key is the field name
+type jsonField struct {
+	key      string
+	untagged bool
+	index    []int
+	typ      reflect.Type
+}
+
+// jsonFields the json fields of struct type t, including the promoted fields of untagged
+// embedded structs which are not shadowed
+func jsonFields(t reflect.Type) []jsonField {
+	var fields, promoted []jsonField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				for _, f := range jsonFields(embedded) {
+					f.index = append([]int{i}, f.index...)
+					promoted = append(promoted, f)
+				}
+
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			fields = append(fields, jsonField{key: field.Name, untagged: true, index: []int{i}, typ: field.Type})
+		} else {
+			fields = append(fields, jsonField{key: name, index: []int{i}, typ: field.Type})
+		}
+	}
+
+	for _, f := range promoted {
+		if findField(fields, f.key, false) == nil {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// findField the field with key, compared case insensitive like encoding/json does,
+// only the untagged fields if untagged
+func findField(fields []jsonField, key string, untagged bool) *jsonField {
+	for i := range fields {
+		if fields[i].key == key && (fields[i].untagged || !untagged) {
+			return &fields[i]
+		}
+	}
+
+	for i := range fields {
+		if strings.EqualFold(fields[i].key, key) && (fields[i].untagged || !untagged) {
+			return &fields[i]
+		}
+	}
+
+	return nil
+}
+
+// renameFields convert the keys of decoded tree which come from untagged struct fields of
+// value to naming, map keys and tagged fields are left as-is
+func renameFields(tree interface{}, value reflect.Value, naming KeyNaming) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return tree
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return tree
+	}
+
+	if value.Type().Implements(marshalerType) || reflect.PtrTo(value.Type()).Implements(marshalerType) {
+		return tree
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		node, ok := tree.(map[string]interface{})
+
+		if !ok {
+			return tree
+		}
+
+		fields := jsonFields(value.Type())
+		renamed := make(map[string]interface{}, len(node))
+
+		for k, v := range node {
+			field := findField(fields, k, false)
+
+			if field == nil || field.key != k {
+				renamed[k] = v
+				continue
+			}
+
+			if field.untagged {
+				k = convertKey(k, naming)
+			}
+
+			if fieldValue, err := value.FieldByIndexErr(field.index); err == nil {
+				v = renameFields(v, fieldValue, naming)
+			}
+
+			renamed[k] = v
+		}
+
+		return renamed
+	case reflect.Map:
+		node, ok := tree.(map[string]interface{})
+
+		if !ok || value.Type().Key().Kind() != reflect.String {
+			return tree
+		}
+
+		iter := value.MapRange()
+
+		for iter.Next() {
+			if v, ok := node[iter.Key().String()]; ok {
+				node[iter.Key().String()] = renameFields(v, iter.Value(), naming)
+			}
+		}
+
+		return node
+	case reflect.Slice, reflect.Array:
+		node, ok := tree.([]interface{})
+
+		if !ok {
+			return tree
+		}
+
+		for i := 0; i < len(node) && i < value.Len(); i++ {
+			node[i] = renameFields(node[i], value.Index(i), naming)
+		}
+
+		return node
+	default:
+		return tree
+	}
+}
+
+// matchFields turn the keys of decoded tree which match an untagged struct field of t
+// in any naming into the field name, keys of map and interface{} targets are left as-is
+func matchFields(tree interface{}, t reflect.Type) interface{} {
+	if t == nil {
+		return tree
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return tree
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		node, ok := tree.(map[string]interface{})
+
+		if !ok {
+			return tree
+		}
+
+		fields := jsonFields(t)
+		renamed := make(map[string]interface{}, len(node))
+
+		for k, v := range node {
+			if field := findField(fields, k, false); field != nil {
+				renamed[k] = matchFields(v, field.typ)
+			} else if field := findField(fields, fieldName(k), true); field != nil {
+				renamed[field.key] = matchFields(v, field.typ)
+			} else {
+				renamed[k] = v
+			}
+		}
+
+		return renamed
+	case reflect.Map:
+		node, ok := tree.(map[string]interface{})
+
+		if !ok {
+			return tree
+		}
+
+		for k, v := range node {
+			node[k] = matchFields(v, t.Elem())
+		}
+
+		return node
+	case reflect.Slice, reflect.Array:
+		node, ok := tree.([]interface{})
+
+		if !ok {
+			return tree
+		}
+
+		for i, v := range node {
+			node[i] = matchFields(v, t.Elem())
+		}
+
+		return node
+	default:
+		return tree
+	}
+}
+
+// splitWords split a Go identifier into words, keeping acronyms: UserIDValue => User ID Value
+func splitWords(key string) []string {
+	runes := []rune(key)
+
+	var words []string
+
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+
+		boundary := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(words, string(runes[start:]))
+}
+
+func convertKey(key string, naming KeyNaming) string {
+	words := splitWords(key)
+
+	switch naming {
+	case SnakeCase:
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+
+		return strings.Join(words, "_")
+	case CamelCase:
+		words[0] = strings.ToLower(words[0])
+
+		return strings.Join(words, "")
+	default:
+		return key
+	}
+}
+
+// fieldName turn a snake_case or camelCase key into the matching Go field name
+func fieldName(key string) string {
+	var builder strings.Builder
+
+	for _, part := range strings.Split(key, "_") {
+		runes := []rune(part)
+
+		if len(runes) == 0 {
+			continue
+		}
+
+		runes[0] = unicode.ToUpper(runes[0])
+
+		builder.WriteString(string(runes))
+	}
+
+	return builder.String()
+}
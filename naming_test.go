@@ -0,0 +1,124 @@
+package restclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type namingUser struct {
+	UserID    int
+	FirstName string
+	Nickname  string `json:"nick"`
+}
+
+func TestConvertKey(t *testing.T) {
+	require.Equal(t, "user_id", convertKey("UserID", SnakeCase))
+	require.Equal(t, "http_server_url", convertKey("HTTPServerURL", SnakeCase))
+	require.Equal(t, "first_name", convertKey("FirstName", SnakeCase))
+	require.Equal(t, "userID", convertKey("UserID", CamelCase))
+	require.Equal(t, "firstName", convertKey("FirstName", CamelCase))
+	require.Equal(t, "UserId", fieldName("user_id"))
+	require.Equal(t, "FirstName", fieldName("firstName"))
+}
+
+func TestWithKeyNaming(t *testing.T) {
+	var body map[string]interface{}
+	var query url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"user":{"user_id":7,"first_name":"test","nick":"t"}}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithKeyNaming(SnakeCase))
+
+	user := &namingUser{UserID: 1, FirstName: "first", Nickname: "nick"}
+
+	result := client.POST("/users", user)
+
+	require.True(t, result.OK())
+	require.Equal(t, map[string]interface{}{"user_id": 1.0, "first_name": "first", "nick": "nick"}, body)
+
+	var v namingUser
+
+	require.NoError(t, result.Value("user", &v))
+	require.Equal(t, namingUser{UserID: 7, FirstName: "test", Nickname: "t"}, v)
+
+	require.True(t, client.GET("/users", user).OK())
+	require.Equal(t, "1", query.Get("user_id"))
+	require.Equal(t, "first", query.Get("first_name"))
+
+	require.True(t, client.GET("/users", user, WithRequestKeyNaming(CamelCase)).OK())
+	require.Equal(t, "1", query.Get("userID"))
+	require.Equal(t, "first", query.Get("firstName"))
+
+	require.True(t, New(server.URL).POST("/users", user).OK())
+	require.Equal(t, map[string]interface{}{"UserID": 1.0, "FirstName": "first", "nick": "nick"}, body)
+}
+
+func TestKeyNamingGenericTargets(t *testing.T) {
+	var body map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"user_id":1,"items":[{"item_name":"x"}]}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithKeyNaming(SnakeCase))
+
+	result := client.GET("/users", nil)
+
+	require.True(t, result.OK())
+
+	var m map[string]interface{}
+
+	require.NoError(t, result.Into(&m))
+	require.Equal(t, map[string]interface{}{"user_id": 1.0, "items": []interface{}{map[string]interface{}{"item_name": "x"}}}, m)
+
+	v, err := result.Any()
+
+	require.NoError(t, err)
+	require.Equal(t, m, v)
+
+	var items []map[string]string
+
+	require.NoError(t, result.Value("items", &items))
+	require.Equal(t, []map[string]string{{"item_name": "x"}}, items)
+
+	type item struct {
+		ItemName string
+	}
+
+	var structs struct {
+		UserID int
+		Items  []item
+	}
+
+	require.NoError(t, result.Into(&structs))
+	require.Equal(t, 1, structs.UserID)
+	require.Equal(t, []item{{ItemName: "x"}}, structs.Items)
+
+	request := struct {
+		UserID int
+		Labels map[string]string
+		Kind   string `json:"Kind"`
+	}{UserID: 1, Labels: map[string]string{"TeamName": "a"}, Kind: "k"}
+
+	require.True(t, client.POST("/users", request).OK())
+	require.Equal(t, map[string]interface{}{"user_id": 1.0, "labels": map[string]interface{}{"TeamName": "a"}, "Kind": "k"}, body)
+
+	require.True(t, client.POST("/users", map[string]interface{}{"UserID": 1}).OK())
+	require.Equal(t, map[string]interface{}{"UserID": 1.0}, body)
+}
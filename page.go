@@ -0,0 +1,188 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PageMeta pagination metadata of a list envelope
+type PageMeta struct {
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// PageKeys envelope key names, an empty key falls back to the default candidates
+type PageKeys struct {
+	Items      string // default data, items or results
+	Meta       string // default meta or pagination
+	Total      string // default total
+	Page       string // default page
+	NextCursor string // default next_cursor, next or cursor
+}
+
+var defaultPageKeys = map[string][]string{
+	"items":      {"data", "items", "results"},
+	"meta":       {"meta", "pagination"},
+	"total":      {"total"},
+	"page":       {"page"},
+	"nextCursor": {"next_cursor", "next", "cursor"},
+}
+
+// PageOption IntoPage option
+type PageOption func(keys *PageKeys)
+
+// WithPageKeys override the envelope key names of IntoPage
+func WithPageKeys(keys PageKeys) PageOption {
+	return func(target *PageKeys) {
+		*target = keys
+	}
+}
+
+// IntoPage decode a list envelope like {"data":[...],"meta":{"total":1,"page":1,"next_cursor":""}}
+// into the items and the pagination metadata
+func IntoPage[T any](r Result, options ...PageOption) (items []T, meta PageMeta, err error) {
+	if err := r.Error(); err != nil {
+		return nil, meta, err
+	}
+
+	var keys PageKeys
+
+	for _, option := range options {
+		option(&keys)
+	}
+
+	var envelope map[string]json.RawMessage
+
+	if err := json.Unmarshal(r.Bytes(), &envelope); err != nil {
+		return nil, meta, fmt.Errorf("unmarshal page err %s\n%s", err, string(r.Bytes()))
+	}
+
+	data, key, ok := pickPageKey(envelope, keys.Items, "items")
+
+	if !ok {
+		return nil, meta, fmt.Errorf("unknown page items\n%s", string(r.Bytes()))
+	}
+
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, meta, fmt.Errorf("unmarshal page items(%s) err %s\n%s", key, err, string(data))
+	}
+
+	data, key, ok = pickPageKey(envelope, keys.Meta, "meta")
+
+	if !ok {
+		return items, meta, nil
+	}
+
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, meta, fmt.Errorf("unmarshal page meta(%s) err %s\n%s", key, err, string(data))
+	}
+
+	targets := []struct {
+		key     string
+		name    string
+		pointer interface{}
+	}{
+		{keys.Total, "total", &meta.Total},
+		{keys.Page, "page", &meta.Page},
+		{keys.NextCursor, "nextCursor", &meta.NextCursor},
+	}
+
+	for _, target := range targets {
+		data, key, ok := pickPageKey(fields, target.key, target.name)
+
+		if !ok || string(data) == "null" {
+			continue
+		}
+
+		if err := json.Unmarshal(data, target.pointer); err != nil {
+			return nil, meta, fmt.Errorf("unmarshal page meta(%s) err %s\n%s", key, err, string(data))
+		}
+	}
+
+	return items, meta, nil
+}
+
+// pickPageKey the value of key, or of the first default candidate of name present in fields
+func pickPageKey(fields map[string]json.RawMessage, key string, name string) (json.RawMessage, string, bool) {
+	candidates := defaultPageKeys[name]
+
+	if key != "" {
+		candidates = []string{key}
+	}
+
+	for _, candidate := range candidates {
+		if data, ok := fields[candidate]; ok {
+			return data, candidate, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// defaultMaxPages CollectAll page cap unless WithMaxPages
+const defaultMaxPages = 100
+
+// WithMaxPages stop CollectAll with an error after n pages, the default is 100
+func WithMaxPages(n int) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).maxPages = n
+	}
+}
+
+// CollectAll GET path and follow the pages, accumulating the items of every page decoded as
+// IntoPage does. the next page is requested with the cursor query param while the meta has a
+// next cursor, or else with the page query param while fewer than total items are collected
+func CollectAll[T any](c Client, path string, request interface{}, options ...Option) ([]T, error) {
+	var all []T
+
+	next := options
+
+	for pages := 1; ; pages++ {
+		result := c.GET(path, request, next...)
+
+		items, meta, err := IntoPage[T](result)
+
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, items...)
+
+		params, ok := nextPage(meta, len(items), len(all))
+
+		if !ok {
+			return all, nil
+		}
+
+		if pages >= maxPages(result) {
+			return all, fmt.Errorf("collect %s stopped after %d pages", path, pages)
+		}
+
+		next = append(options[:len(options):len(options)], WithQueryParamsFromStruct(params))
+	}
+}
+
+// nextPage the query params of the page after meta, false on the last page
+func nextPage(meta PageMeta, items int, collected int) (map[string]interface{}, bool) {
+	if meta.NextCursor != "" {
+		return map[string]interface{}{"cursor": meta.NextCursor}, true
+	}
+
+	if meta.Page > 0 && items > 0 && collected < meta.Total {
+		return map[string]interface{}{"page": meta.Page + 1}, true
+	}
+
+	return nil, false
+}
+
+func maxPages(result Result) int {
+	if impl, ok := result.(*resultImpl); ok && impl.settings.maxPages > 0 {
+		return impl.settings.maxPages
+	}
+
+	return defaultMaxPages
+}
@@ -0,0 +1,100 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pageItem struct {
+	ID int `json:"id"`
+}
+
+func TestIntoPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/custom":
+			w.Write([]byte(`{"rows":[{"id":3}],"paging":{"count":7,"page":3,"after":"c3"}}`))
+		default:
+			w.Write([]byte(`{"data":[{"id":1},{"id":2}],"meta":{"total":7,"page":1,"next_cursor":"c1"}}`))
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	items, meta, err := IntoPage[pageItem](client.GET("/items", nil))
+
+	require.NoError(t, err)
+	require.Equal(t, []pageItem{{ID: 1}, {ID: 2}}, items)
+	require.Equal(t, PageMeta{Total: 7, Page: 1, NextCursor: "c1"}, meta)
+
+	items, meta, err = IntoPage[pageItem](client.GET("/custom", nil), WithPageKeys(PageKeys{
+		Items:      "rows",
+		Meta:       "paging",
+		Total:      "count",
+		NextCursor: "after",
+	}))
+
+	require.NoError(t, err)
+	require.Equal(t, []pageItem{{ID: 3}}, items)
+	require.Equal(t, PageMeta{Total: 7, Page: 3, NextCursor: "c3"}, meta)
+
+	_, _, err = IntoPage[pageItem](client.GET("/custom", nil))
+
+	require.Error(t, err)
+}
+
+func TestCollectAll(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"data":[{"id":1},{"id":2}],"meta":{"next_cursor":"c1"}}`,
+		"c1": `{"data":[{"id":3}],"meta":{"next_cursor":"c2"}}`,
+		"c2": `{"data":[{"id":4},{"id":5}],"meta":{"next_cursor":""}}`,
+	}
+
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		require.Equal(t, "10", r.URL.Query().Get("limit"))
+
+		w.Write([]byte(pages[r.URL.Query().Get("cursor")]))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	items, err := CollectAll[pageItem](client, "/items", map[string]int{"limit": 10})
+
+	require.NoError(t, err)
+	require.Equal(t, []pageItem{{1}, {2}, {3}, {4}, {5}}, items)
+	require.Equal(t, 3, requests)
+
+	items, err = CollectAll[pageItem](client, "/items", map[string]int{"limit": 10}, WithMaxPages(2))
+
+	require.Error(t, err)
+	require.Equal(t, []pageItem{{1}, {2}, {3}}, items)
+}
+
+func TestCollectAllPageNumbers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			w.Write([]byte(`{"data":[{"id":1},{"id":2}],"meta":{"total":3,"page":1}}`))
+		default:
+			w.Write([]byte(`{"data":[{"id":3}],"meta":{"total":3,"page":2}}`))
+		}
+	}))
+
+	defer server.Close()
+
+	items, err := CollectAll[pageItem](New(server.URL), "/items", nil)
+
+	require.NoError(t, err)
+	require.Equal(t, []pageItem{{1}, {2}, {3}}, items)
+}
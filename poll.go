@@ -0,0 +1,60 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PollUntil issues the GET request every interval until done reports true or returns an error,
+// a context bound with WithContext cancels the polling. the in flight request is bounded by
+// the timeout as well, a timeout error is returned with the last result
+func (client *clientImpl) PollUntil(path string, request interface{}, done func(Result) (bool, error), interval, timeout time.Duration, options ...Option) (Result, error) {
+
+	deadline := client.clock.Now().Add(timeout)
+
+	for {
+		var ctx context.Context = context.Background()
+		var cancel context.CancelFunc = func() {}
+
+		// bind the deadline to the context chosen by the caller options
+		bind := func(request *http.Request) {
+			ctx = request.Context()
+
+			var bounded context.Context
+
+			bounded, cancel = context.WithTimeout(ctx, deadline.Sub(client.clock.Now()))
+
+			*request = *request.WithContext(bounded)
+		}
+
+		result := client.GET(path, request, append(options[:len(options):len(options)], bind)...)
+
+		cancel()
+
+		ok, err := done(result)
+
+		if err != nil {
+			return result, err
+		}
+
+		if ok {
+			return result, nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if !client.clock.Now().Add(interval).Before(deadline) {
+			return result, fmt.Errorf("poll %s timeout after %s", path, timeout)
+		}
+
+		select {
+		case <-client.clock.After(interval):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package restclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func jobDone(result Result) (bool, error) {
+	if err := result.Error(); err != nil {
+		return false, err
+	}
+
+	var status string
+
+	if err := result.Value("status", &status); err != nil {
+		return false, err
+	}
+
+	return status == "DONE", nil
+}
+
+func newJobServer(pending int32) (*httptest.Server, *int32) {
+	var polls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "PENDING"
+
+		if atomic.AddInt32(&polls, 1) > pending {
+			status = "DONE"
+		}
+
+		fmt.Fprintf(w, `{"status":"%s"}`, status)
+	}))
+
+	return server, &polls
+}
+
+func TestPollUntil(t *testing.T) {
+	server, polls := newJobServer(3)
+
+	defer server.Close()
+
+	result, err := New(server.URL).PollUntil("/job", nil, jobDone, 10*time.Millisecond, time.Second)
+
+	require.NoError(t, err)
+	require.True(t, result.OK())
+	require.Equal(t, int32(4), atomic.LoadInt32(polls))
+}
+
+func TestPollUntilTimeout(t *testing.T) {
+	server, _ := newJobServer(1000)
+
+	defer server.Close()
+
+	_, err := New(server.URL).PollUntil("/job", nil, jobDone, 10*time.Millisecond, 50*time.Millisecond)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timeout")
+}
+
+func TestPollUntilCancel(t *testing.T) {
+	server, _ := newJobServer(1000)
+
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err := New(server.URL).PollUntil("/job", nil, jobDone, 10*time.Millisecond, time.Minute, WithContext(ctx))
+
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestStatusHandlerPollsAccepted(t *testing.T) {
+	var polls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Header().Set("Location", "/jobs/1")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		status := "PENDING"
+
+		if atomic.AddInt32(&polls, 1) > 2 {
+			status = "DONE"
+		}
+
+		fmt.Fprintf(w, `{"status":"%s"}`, status)
+	}))
+
+	defer server.Close()
+
+	var client Client
+
+	client = New(server.URL, WithStatusHandler(http.StatusAccepted, func(result Result) Result {
+		location := result.Response().Header().Get("Location")
+
+		polled, _ := client.PollUntil(location, nil, jobDone, 10*time.Millisecond, time.Second)
+
+		return polled
+	}))
+
+	result := client.POST("/jobs", map[string]string{"name": "export"})
+
+	done, err := jobDone(result)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Equal(t, int32(3), atomic.LoadInt32(&polls))
+}
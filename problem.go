@@ -0,0 +1,56 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+)
+
+// ProblemContentType RFC 7807 problem details content type
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails RFC 7807 problem details
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+
+	Extensions map[string]interface{} `json:"-"` // members other than the standard ones
+}
+
+// ProblemDetails decode an application/problem+json response body
+func (result *resultImpl) ProblemDetails() (*ProblemDetails, error) {
+	if result.resp == nil {
+		return nil, fmt.Errorf("no response to decode: %s", result.Error())
+	}
+
+	contentType := result.resp.Header().Get("Content-Type")
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != ProblemContentType {
+		return nil, fmt.Errorf("response content type %q is not %s", contentType, ProblemContentType)
+	}
+
+	var problem ProblemDetails
+
+	if err := json.Unmarshal(result.resp.Body(), &problem); err != nil {
+		return nil, fmt.Errorf("unmarshal problem details err %s\n%s", err, result.bodySnippet())
+	}
+
+	var members map[string]interface{}
+
+	if err := json.Unmarshal(result.resp.Body(), &members); err != nil {
+		return nil, fmt.Errorf("unmarshal problem details err %s\n%s", err, result.bodySnippet())
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+
+	if len(members) > 0 {
+		problem.Extensions = members
+	}
+
+	return &problem, nil
+}
@@ -0,0 +1,49 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"code":1,"msg":"invalid"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{
+			"type": "https://example.com/probs/invalid-params",
+			"title": "Your request parameters didn't validate.",
+			"status": 422,
+			"detail": "age must be a positive integer",
+			"instance": "/users/1",
+			"invalid-params": [{"name": "age", "reason": "must be a positive integer"}]
+		}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	problem, err := client.POST("/users", nil).ProblemDetails()
+
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/probs/invalid-params", problem.Type)
+	require.Equal(t, "Your request parameters didn't validate.", problem.Title)
+	require.Equal(t, 422, problem.Status)
+	require.Equal(t, "age must be a positive integer", problem.Detail)
+	require.Equal(t, "/users/1", problem.Instance)
+	require.Equal(t, []interface{}{map[string]interface{}{"name": "age", "reason": "must be a positive integer"}}, problem.Extensions["invalid-params"])
+
+	_, err = client.POST("/plain", nil).ProblemDetails()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not application/problem+json")
+}
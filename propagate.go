@@ -0,0 +1,54 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+)
+
+type inboundHeadersKey struct{}
+
+// ContextWithHeaders attach the headers of an inbound request to ctx, to be propagated
+// to the outbound requests bound to ctx with WithHeaderMapping
+func ContextWithHeaders(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, inboundHeadersKey{}, header.Clone())
+}
+
+// HeadersFromContext the inbound headers attached with ContextWithHeaders, nil if none
+func HeadersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(inboundHeadersKey{}).(http.Header)
+
+	return header
+}
+
+// WithHeaderMapping propagate the inbound header of every mapping key attached to the request
+// context as the outbound header named by its value, e.g. {"X-Correlation-ID": "X-Request-ID"}.
+// map a header to itself to propagate it unchanged. it runs after all other options, so it
+// sees the context bound by WithContext, and headers set by options are not overwritten
+func WithHeaderMapping(mapping map[string]string) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+		settings.headerMappings = append(settings.headerMappings, mapping)
+	}
+}
+
+func propagateHeaders(request *http.Request, mappings []map[string]string) {
+	inbound := HeadersFromContext(request.Context())
+
+	if inbound == nil {
+		return
+	}
+
+	for _, mapping := range mappings {
+		for from, to := range mapping {
+			values := inbound.Values(from)
+
+			if len(values) == 0 || request.Header.Get(to) != "" {
+				continue
+			}
+
+			for _, value := range values {
+				request.Header.Add(to, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package restclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeaderMapping(t *testing.T) {
+	var received http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithDefaultOptions(WithHeaderMapping(map[string]string{
+		"X-Correlation-ID": "X-Request-ID",
+		"X-Tenant":         "X-Tenant",
+	})))
+
+	inbound := http.Header{}
+	inbound.Set("X-Correlation-ID", "c-1")
+	inbound.Set("X-Tenant", "acme")
+	inbound.Set("Cookie", "session=secret")
+
+	ctx := ContextWithHeaders(context.Background(), inbound)
+
+	require.True(t, client.GET("/", nil, WithContext(ctx)).OK())
+
+	require.Equal(t, "c-1", received.Get("X-Request-ID"))
+	require.Equal(t, "", received.Get("X-Correlation-ID"))
+	require.Equal(t, "acme", received.Get("X-Tenant"))
+	require.Equal(t, "", received.Get("Cookie"))
+
+	// explicit headers win
+	setTenant := func(request *http.Request) {
+		request.Header.Set("X-Tenant", "other")
+	}
+
+	require.True(t, client.GET("/", nil, WithContext(ctx), setTenant).OK())
+	require.Equal(t, "other", received.Get("X-Tenant"))
+
+	require.True(t, client.GET("/", nil).OK())
+	require.Equal(t, "", received.Get("X-Request-ID"))
+}
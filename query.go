@@ -0,0 +1,188 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// QueryEncoder encode a GET/DELETE request value into query params, naming is the key
+// naming of the request, encoders which do not rename keys may ignore it
+type QueryEncoder interface {
+	Encode(request interface{}, naming KeyNaming) (url.Values, error)
+}
+
+// QueryEncoderFunc adapt a func to QueryEncoder
+type QueryEncoderFunc func(request interface{}, naming KeyNaming) (url.Values, error)
+
+// Encode call f
+func (f QueryEncoderFunc) Encode(request interface{}, naming KeyNaming) (url.Values, error) {
+	return f(request, naming)
+}
+
+var (
+	// FlatQuery the default encoder, one param per top level field formatted with %v
+	FlatQuery QueryEncoder = QueryEncoderFunc(encodeFlatQuery)
+	// DeepObjectQuery nested objects as filter[status]=active, arrays as ids[]=1&ids[]=2
+	DeepObjectQuery QueryEncoder = QueryEncoderFunc(encodeDeepObjectQuery)
+	// IndexedQuery nested objects and arrays as items[0][name]=x&items[0][qty]=2
+	IndexedQuery QueryEncoder = QueryEncoderFunc(encodeIndexedQuery)
+	// BracketArrayQuery arrays as ids[]=1&ids[]=2, other fields as FlatQuery does
+	BracketArrayQuery QueryEncoder = QueryEncoderFunc(encodeBracketArrayQuery)
+	// CommaArrayQuery arrays as ids=1,2, other fields as FlatQuery does
+	CommaArrayQuery QueryEncoder = QueryEncoderFunc(encodeCommaArrayQuery)
+)
+
+// WithQueryEncoder encode the GET/DELETE request and WithQueryParamsFromStruct values with encoder,
+// the default is FlatQuery
+func WithQueryEncoder(encoder QueryEncoder) ClientOption {
+	return func(client *clientImpl) {
+		client.queryEncoder = encoder
+	}
+}
+
+func encodeFlatQuery(request interface{}, naming KeyNaming) (url.Values, error) {
+	params, err := requestToMap(request, naming)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values, len(params))
+
+	for k, v := range params {
+		values.Set(k, v)
+	}
+
+	return values, nil
+}
+
+// queryFields the top level fields of request after json encoding, nil for a nil request
+func queryFields(request interface{}, naming KeyNaming) (map[string]interface{}, error) {
+	buff, err := marshalJSON(request, naming)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := decodeTree(buff)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tree == nil {
+		return nil, nil
+	}
+
+	fields, ok := tree.(map[string]interface{})
+
+	if !ok {
+		return nil, fmt.Errorf("query request must encode to a json object, got %T", tree)
+	}
+
+	return fields, nil
+}
+
+func encodeDeepObjectQuery(request interface{}, naming KeyNaming) (url.Values, error) {
+	return encodeNestedQuery(request, naming, false)
+}
+
+func encodeIndexedQuery(request interface{}, naming KeyNaming) (url.Values, error) {
+	return encodeNestedQuery(request, naming, true)
+}
+
+// encodeNestedQuery encode nested objects with bracket keys, array items with their index
+// in the brackets if indexed or else with empty brackets
+func encodeNestedQuery(request interface{}, naming KeyNaming, indexed bool) (url.Values, error) {
+	fields, err := queryFields(request, naming)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+
+	for k, v := range fields {
+		addNested(values, k, v, indexed)
+	}
+
+	return values, nil
+}
+
+func addNested(values url.Values, key string, node interface{}, indexed bool) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for k, v := range node {
+			addNested(values, key+"["+k+"]", v, indexed)
+		}
+	case []interface{}:
+		for i, v := range node {
+			if indexed {
+				addNested(values, fmt.Sprintf("%s[%d]", key, i), v, indexed)
+			} else {
+				addNested(values, key+"[]", v, indexed)
+			}
+		}
+	case nil:
+	default:
+		values.Add(key, queryScalar(node))
+	}
+}
+
+func encodeBracketArrayQuery(request interface{}, naming KeyNaming) (url.Values, error) {
+	return encodeArrayQuery(request, naming, func(values url.Values, key string, items []string) {
+		values[key+"[]"] = items
+	})
+}
+
+func encodeCommaArrayQuery(request interface{}, naming KeyNaming) (url.Values, error) {
+	return encodeArrayQuery(request, naming, func(values url.Values, key string, items []string) {
+		values.Set(key, strings.Join(items, ","))
+	})
+}
+
+// encodeArrayQuery encode top level arrays with add, the other fields one param each
+func encodeArrayQuery(request interface{}, naming KeyNaming, add func(values url.Values, key string, items []string)) (url.Values, error) {
+	fields, err := queryFields(request, naming)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+
+	for k, v := range fields {
+		switch v := v.(type) {
+		case []interface{}:
+			items := make([]string, 0, len(v))
+
+			for _, item := range v {
+				items = append(items, queryScalar(item))
+			}
+
+			add(values, k, items)
+		case nil:
+		default:
+			values.Set(k, queryScalar(v))
+		}
+	}
+
+	return values, nil
+}
+
+// queryScalar format a decoded json value, nested objects and arrays are kept as json
+func queryScalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		buff, _ := json.Marshal(v)
+		return string(buff)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
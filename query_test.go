@@ -0,0 +1,100 @@
+package restclient
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type queryFilter struct {
+	Status string `json:"status"`
+	Owner  struct {
+		ID int `json:"id"`
+	} `json:"owner"`
+}
+
+type queryRequest struct {
+	Filter queryFilter `json:"filter"`
+	IDs    []int       `json:"ids"`
+	Limit  int         `json:"limit"`
+}
+
+func queryOf(t *testing.T, rawURL string) url.Values {
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	return u.Query()
+}
+
+func TestDeepObjectQuery(t *testing.T) {
+	request := queryRequest{IDs: []int{1, 2}, Limit: 10}
+	request.Filter.Status = "active"
+	request.Filter.Owner.ID = 7
+
+	rawURL, err := New("http://test.com", WithQueryEncoder(DeepObjectQuery)).BuildURL("/items", request)
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{
+		"filter[status]":    {"active"},
+		"filter[owner][id]": {"7"},
+		"ids[]":             {"1", "2"},
+		"limit":             {"10"},
+	}, queryOf(t, rawURL))
+}
+
+func TestBracketArrayQuery(t *testing.T) {
+	client := New("http://test.com", WithQueryEncoder(BracketArrayQuery))
+
+	rawURL, err := client.BuildURL("/items", map[string]interface{}{"ids": []int{1, 2}, "sort": "name"})
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{"ids[]": {"1", "2"}, "sort": {"name"}}, queryOf(t, rawURL))
+
+	rawURL, err = client.BuildURL("/items", nil, WithQueryParamsFromStruct(map[string]interface{}{"tags": []string{"a", "b"}}))
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{"tags[]": {"a", "b"}}, queryOf(t, rawURL))
+}
+
+func TestCommaArrayQuery(t *testing.T) {
+	rawURL, err := New("http://test.com", WithQueryEncoder(CommaArrayQuery)).BuildURL("/items", map[string]interface{}{"ids": []int{1, 2}})
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{"ids": {"1,2"}}, queryOf(t, rawURL))
+}
+
+func TestQueryEncoderFunc(t *testing.T) {
+	encoder := QueryEncoderFunc(func(request interface{}, naming KeyNaming) (url.Values, error) {
+		return url.Values{"q": {"custom"}}, nil
+	})
+
+	rawURL, err := New("http://test.com", WithQueryEncoder(encoder)).BuildURL("/items", struct{}{})
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{"q": {"custom"}}, queryOf(t, rawURL))
+}
+
+func TestIndexedQuery(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+		Qty  int    `json:"qty"`
+	}
+
+	request := map[string]interface{}{
+		"items": []item{{"x", 2}, {"y", 1}},
+		"tags":  []string{"a", "b"},
+	}
+
+	rawURL, err := New("http://test.com", WithQueryEncoder(IndexedQuery)).BuildURL("/orders", request)
+	require.NoError(t, err)
+
+	require.Equal(t, url.Values{
+		"items[0][name]": {"x"},
+		"items[0][qty]":  {"2"},
+		"items[1][name]": {"y"},
+		"items[1][qty]":  {"1"},
+		"tags[0]":        {"a"},
+		"tags[1]":        {"b"},
+	}, queryOf(t, rawURL))
+}
@@ -0,0 +1,102 @@
+package restclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RecordedRequest a request sent through a Recorder and the result it produced
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte // the serialized body, before WithAutoGzip compression
+	Result Result
+}
+
+// Recorder Client decorator recording every request sent, for asserting in tests what
+// was sent. each request of GetMany, PollUntil or UploadResumable is recorded on its own
+type Recorder struct {
+	Client
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// NewRecorder record the requests sent through client, which must be built with New or be
+// a decorator of such a client, other Client implementations record nothing
+func NewRecorder(client Client) *Recorder {
+	return &Recorder{Client: client}
+}
+
+// Requests the recorded requests in the order they completed
+func (recorder *Recorder) Requests() []RecordedRequest {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return append([]RecordedRequest(nil), recorder.requests...)
+}
+
+// Reset drop the recorded requests
+func (recorder *Recorder) Reset() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.requests = nil
+}
+
+func (recorder *Recorder) record(request RecordedRequest) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.requests = append(recorder.requests, request)
+}
+
+func (recorder *Recorder) with(options []Option) []Option {
+	return append(options[:len(options):len(options)], func(request *http.Request) {
+		settingsOf(request.Context()).record = recorder.record
+	})
+}
+
+func (recorder *Recorder) POST(path string, request interface{}, options ...Option) Result {
+	return recorder.Client.POST(path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) POSTForm(path string, form url.Values, options ...Option) Result {
+	return recorder.Client.POSTForm(path, form, recorder.with(options)...)
+}
+
+func (recorder *Recorder) GET(path string, request interface{}, options ...Option) Result {
+	return recorder.Client.GET(path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) DELETE(path string, request interface{}, options ...Option) Result {
+	return recorder.Client.DELETE(path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) POSTE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return recorder.Client.POSTE(ctx, path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) GETE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return recorder.Client.GETE(ctx, path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) DELETEE(ctx context.Context, path string, request interface{}, options ...Option) (Result, error) {
+	return recorder.Client.DELETEE(ctx, path, request, recorder.with(options)...)
+}
+
+func (recorder *Recorder) GetMany(path string, requests []interface{}, concurrency int, options ...Option) []Result {
+	return recorder.Client.GetMany(path, requests, concurrency, recorder.with(options)...)
+}
+
+func (recorder *Recorder) PollUntil(path string, request interface{}, done func(Result) (bool, error), interval, timeout time.Duration, options ...Option) (Result, error) {
+	return recorder.Client.PollUntil(path, request, done, interval, timeout, recorder.with(options)...)
+}
+
+func (recorder *Recorder) UploadResumable(path string, r io.ReaderAt, size int64, chunkSize int64, options ...Option) Result {
+	return recorder.Client.UploadResumable(path, r, size, chunkSize, recorder.with(options)...)
+}
@@ -0,0 +1,40 @@
+package restclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(New("http://api.test", WithSchemeTransport("http", &recordTransport{name: "api"})))
+
+	var client Client = recorder
+
+	require.True(t, client.POST("/users", map[string]string{"name": "bob"}, WithJWToken("abc")).OK())
+	require.True(t, client.GET("/users", map[string]int{"page": 2}).OK())
+
+	requests := recorder.Requests()
+
+	require.Len(t, requests, 2)
+
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "http://api.test/users", requests[0].URL)
+	require.Equal(t, "Bearer abc", requests[0].Header.Get("Authorization"))
+	require.JSONEq(t, `{"name":"bob"}`, string(requests[0].Body))
+	require.True(t, requests[0].Result.OK())
+
+	require.Equal(t, http.MethodGet, requests[1].Method)
+	require.Equal(t, "http://api.test/users?page=2", requests[1].URL)
+	require.Empty(t, requests[1].Body)
+
+	results := client.GetMany("/users", []interface{}{map[string]int{"id": 1}, map[string]int{"id": 2}}, 2)
+
+	require.Len(t, results, 2)
+	require.Len(t, recorder.Requests(), 4)
+
+	recorder.Reset()
+
+	require.Empty(t, recorder.Requests())
+}
@@ -0,0 +1,139 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveKeys query params and json keys containing one of these are redacted
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "auth", "key", "signature", "credential"}
+
+// WithErrorIncludeRequest append the request method, url and body to Error() of a failed
+// response. with redact the values of json body keys which look like secrets are replaced,
+// and a body which is not json is left out. secret query params of the url are always redacted
+func WithErrorIncludeRequest(redact bool) Option {
+	return func(request *http.Request) {
+		settings := settingsOf(request.Context())
+		settings.includeRequest = true
+		settings.redactRequest = redact
+	}
+}
+
+// captureBody keep a copy of the serialized request body for Error()
+func captureBody(request *http.Request, settings *requestSettings) error {
+	body, err := request.GetBody()
+
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+
+	settings.requestBody, err = ioutil.ReadAll(body)
+
+	return err
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestURL the request url for Error(), with the secret query params redacted
+func (result *resultImpl) requestURL() string {
+	if result.resp.Request.RawRequest != nil {
+		return redactURL(result.resp.Request.RawRequest.URL)
+	}
+
+	u, err := url.Parse(result.resp.Request.URL)
+
+	if err != nil {
+		return result.resp.Request.URL
+	}
+
+	return redactURL(u)
+}
+
+// redactURL u with the secret query params redacted
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	query := redactedURL.Query()
+
+	for k := range query {
+		if sensitiveKey(k) {
+			query.Set(k, redacted)
+		}
+	}
+
+	redactedURL.RawQuery = query.Encode()
+
+	return redactedURL.String()
+}
+
+// requestMessage the request section of Error(), empty unless WithErrorIncludeRequest
+func (result *resultImpl) requestMessage() string {
+	if !result.settings.includeRequest {
+		return ""
+	}
+
+	msg := fmt.Sprintf(" request(%s %s)", result.resp.Request.Method, result.requestURL())
+
+	if len(result.settings.requestBody) == 0 {
+		return msg
+	}
+
+	body := result.settings.requestBody
+
+	if result.settings.redactRequest {
+		var err error
+
+		if body, err = redactJSON(body); err != nil {
+			return fmt.Sprintf("%s body(%d bytes %s)", msg, len(result.settings.requestBody), redacted)
+		}
+	}
+
+	return fmt.Sprintf("%s body(%s)", msg, result.snippet(body))
+}
+
+// redactJSON replace the values of sensitive keys anywhere in the json document
+func redactJSON(data []byte) ([]byte, error) {
+	tree, err := decodeTree(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redactTree(tree))
+}
+
+func redactTree(tree interface{}) interface{} {
+	switch node := tree.(type) {
+	case map[string]interface{}:
+		for k, v := range node {
+			if sensitiveKey(k) {
+				node[k] = redacted
+			} else {
+				node[k] = redactTree(v)
+			}
+		}
+	case []interface{}:
+		for i, v := range node {
+			node[i] = redactTree(v)
+		}
+	}
+
+	return tree
+}
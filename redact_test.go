@@ -0,0 +1,63 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newInvalidPayloadServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"msg":"invalid payload"}`))
+	}))
+}
+
+func TestWithErrorIncludeRequest(t *testing.T) {
+	server := newInvalidPayloadServer()
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	body := map[string]interface{}{"name": "bob", "password": "hunter2"}
+
+	err := client.POST("/users", body, WithErrorIncludeRequest(false)).Error()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid payload")
+	require.Contains(t, err.Error(), "request(POST "+server.URL+"/users)")
+	require.Contains(t, err.Error(), `"password":"hunter2"`)
+
+	err = client.POST("/users", body, WithErrorIncludeRequest(true)).Error()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"name":"bob"`)
+	require.Contains(t, err.Error(), `"password":"REDACTED"`)
+	require.NotContains(t, err.Error(), "hunter2")
+
+	err = client.GET("/users", map[string]string{"api_key": "k1", "q": "bob"}, WithErrorIncludeRequest(true)).Error()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "api_key=REDACTED")
+	require.Contains(t, err.Error(), "q=bob")
+	require.NotContains(t, err.Error(), "k1")
+
+	err = client.POST("/users", body).Error()
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "request(")
+
+	// the query secrets are redacted without WithErrorIncludeRequest too
+	server404 := httptest.NewServer(http.NotFoundHandler())
+
+	defer server404.Close()
+
+	err = New(server404.URL).GET("/users", map[string]string{"token": "t1", "q": "bob"}).Error()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "token=REDACTED")
+	require.NotContains(t, err.Error(), "t1")
+
+	err = client.GET("/users", map[string]string{"api_key": "k1"}, WithErrorIncludeRequest(false)).Error()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "api_key=REDACTED")
+	require.NotContains(t, err.Error(), "k1")
+}
@@ -0,0 +1,144 @@
+package restclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty"
+)
+
+// TransportErrorKind category of transport errors used to decide whether a request is retried
+type TransportErrorKind int
+
+// transport error kinds, combine them with WithRetryableErrors
+const (
+	TimeoutError TransportErrorKind = 1 << iota
+	ConnRefusedError
+	DNSError
+	TLSError
+)
+
+// defaultRetryableErrors retry the transient failures, a DNS or TLS failure won't heal by retrying
+const defaultRetryableErrors = TimeoutError | ConnRefusedError
+
+type retryPolicy struct {
+	count     int
+	wait      time.Duration
+	retryable TransportErrorKind
+	budget    *retryBudget // nil for unlimited retries
+}
+
+// WithRetry retry a request up to count times, waiting wait between attempts,
+// when it fails with a retryable transport error
+func WithRetry(count int, wait time.Duration) ClientOption {
+	return func(client *clientImpl) {
+		client.retry.count = count
+		client.retry.wait = wait
+	}
+}
+
+// WithRetryableErrors select the transport error kinds which are retried,
+// the default is TimeoutError|ConnRefusedError
+func WithRetryableErrors(kinds ...TransportErrorKind) ClientOption {
+	return func(client *clientImpl) {
+		client.retry.retryable = 0
+
+		for _, kind := range kinds {
+			client.retry.retryable |= kind
+		}
+	}
+}
+
+// WithRetryCount override the client retry count for this request
+func WithRetryCount(n int) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).retryCount = &n
+	}
+}
+
+// WithNoRetry never retry this request, e.g. for a non-idempotent operation
+func WithNoRetry() Option {
+	return WithRetryCount(0)
+}
+
+// transportErrorKind classify err, returns 0 for errors out of any known kind
+func transportErrorKind(err error) TransportErrorKind {
+	var dnsErr *net.DNSError
+
+	if errors.As(err, &dnsErr) {
+		return DNSError
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ConnRefusedError
+	}
+
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateErr x509.CertificateInvalidError
+
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certificateErr) {
+		return TLSError
+	}
+
+	var netErr net.Error
+
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return TimeoutError
+	}
+
+	return 0
+}
+
+// execute send the request, retrying it according to the client retry policy
+func (client *clientImpl) execute(r *resty.Request, method string, url string) (*resty.Response, error) {
+	defer client.checkSlow(method, url, client.clock.Now())
+
+	count := client.retry.count
+
+	if n := settingsOf(r.Context()).retryCount; n != nil {
+		count = *n
+	}
+
+	if client.retry.budget != nil {
+		client.retry.budget.deposit()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if method == http.MethodPost && settingsOf(r.Context()).bodyFunc != nil {
+			if err := client.produceBody(r); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := r.Execute(method, url)
+
+		settingsOf(r.Context()).attempts = attempt + 1
+
+		if err == nil || attempt >= count || r.Context().Err() != nil {
+			return resp, err
+		}
+
+		if transportErrorKind(err)&client.retry.retryable == 0 {
+			return resp, err
+		}
+
+		if client.retry.budget != nil && !client.retry.budget.withdraw(client.clock.Now()) {
+			return resp, err
+		}
+
+		select {
+		case <-client.clock.After(client.retry.wait):
+		case <-r.Context().Done():
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package restclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransportErrorKind(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://test.com", Err: err}
+	}
+
+	require.Equal(t, TimeoutError, transportErrorKind(wrap(&net.OpError{Op: "read", Err: timeoutError{}})))
+	require.Equal(t, ConnRefusedError, transportErrorKind(wrap(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)})))
+	require.Equal(t, DNSError, transportErrorKind(wrap(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "test.invalid"}})))
+	require.Equal(t, TLSError, transportErrorKind(wrap(x509.UnknownAuthorityError{})))
+	require.Equal(t, TLSError, transportErrorKind(wrap(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"})))
+	require.Equal(t, TransportErrorKind(0), transportErrorKind(wrap(errors.New("EOF"))))
+}
+
+// refusedAddr a local address nobody listens on
+func refusedAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	listener.Close()
+
+	return addr
+}
+
+// listenLater start serving on addr after delay, the returned func stops the server
+func listenLater(addr string, delay time.Duration) func() {
+	servers := make(chan *httptest.Server, 1)
+
+	go func() {
+		time.Sleep(delay)
+
+		listener, err := net.Listen("tcp", addr)
+
+		if err != nil {
+			servers <- nil
+			return
+		}
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{}`))
+		}))
+
+		server.Listener = listener
+		server.Start()
+
+		servers <- server
+	}()
+
+	return func() {
+		if server := <-servers; server != nil {
+			server.Close()
+		}
+	}
+}
+
+func TestRetryConnRefused(t *testing.T) {
+	addr := refusedAddr(t)
+
+	defer listenLater(addr, 100*time.Millisecond)()
+
+	result := New("http://"+addr, WithRetry(20, 20*time.Millisecond)).GET("/", nil)
+
+	require.NoError(t, result.Error())
+}
+
+func TestRetryableErrorsOptOut(t *testing.T) {
+	addr := refusedAddr(t)
+
+	client := New("http://"+addr, WithRetry(3, time.Second), WithRetryableErrors(TimeoutError))
+
+	start := time.Now()
+
+	result := client.GET("/", nil)
+
+	require.Error(t, result.Error())
+	require.True(t, time.Since(start) < time.Second)
+}
+
+func TestRetryStopsOnContextDone(t *testing.T) {
+	addr := refusedAddr(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	result := New("http://"+addr, WithRetry(10, time.Second)).GET("/", nil, WithContext(ctx))
+
+	require.Error(t, result.Error())
+	require.True(t, time.Since(start) < time.Second)
+}
+
+func TestWithRetryCount(t *testing.T) {
+	addr := refusedAddr(t)
+
+	defer listenLater(addr, 100*time.Millisecond)()
+
+	client := New("http://"+addr, WithRetry(0, 20*time.Millisecond))
+
+	require.NoError(t, client.GET("/", nil, WithRetryCount(20)).Error())
+}
+
+func TestWithNoRetry(t *testing.T) {
+	addr := refusedAddr(t)
+
+	client := New("http://"+addr, WithRetry(3, time.Second))
+
+	start := time.Now()
+
+	require.Error(t, client.POST("/", nil, WithNoRetry()).Error())
+	require.True(t, time.Since(start) < time.Second)
+}
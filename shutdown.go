@@ -0,0 +1,118 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// shutdown tracks the in flight requests of a client so Shutdown can cancel them
+type shutdown struct {
+	sync.Mutex
+	closed   bool
+	done     chan struct{} // closed by Shutdown, cancels the in flight requests
+	inflight sync.WaitGroup
+}
+
+func newShutdown() *shutdown {
+	return &shutdown{done: make(chan struct{})}
+}
+
+// enter count a request in flight, false once the client is shut down
+func (s *shutdown) enter() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.inflight.Add(1)
+
+	return true
+}
+
+// bind a context of parent cancelled by Shutdown as well
+func (s *shutdown) bind(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// cancelBody raw response body releasing the request context once closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelBody) Close() error {
+	defer body.cancel()
+
+	return body.ReadCloser.Close()
+}
+
+// Shutdown cancel the in flight requests, close the idle connections and wait for the
+// cancelled requests to return until ctx is done. requests issued after Shutdown fail
+// immediately. the bodies of WithRawBody requests being read are cancelled as well
+func (client *clientImpl) Shutdown(ctx context.Context) error {
+	client.shutdown.Lock()
+
+	if !client.shutdown.closed {
+		client.shutdown.closed = true
+		close(client.shutdown.done)
+	}
+
+	client.shutdown.Unlock()
+
+	client.closeIdleConnections()
+
+	drained := make(chan struct{})
+
+	go func() {
+		client.shutdown.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown err %s", ctx.Err())
+	}
+}
+
+func (client *clientImpl) closeIdleConnections() {
+	type idleCloser interface {
+		CloseIdleConnections()
+	}
+
+	transports := []http.RoundTripper{client.transport.base}
+
+	for _, rt := range client.transport.schemes {
+		transports = append(transports, rt)
+	}
+
+	client.transport.tailoredMutex.Lock()
+
+	for _, rt := range client.transport.tailored {
+		transports = append(transports, rt)
+	}
+
+	client.transport.tailoredMutex.Unlock()
+
+	for _, rt := range transports {
+		if closer, ok := rt.(idleCloser); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+}
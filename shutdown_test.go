@@ -0,0 +1,90 @@
+package restclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	entered := make(chan struct{}, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fast" {
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		entered <- struct{}{}
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.True(t, client.GET("/fast", nil).OK())
+
+	results := make(chan Result, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			results <- client.GET("/hang", nil)
+		}()
+	}
+
+	<-entered
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, client.Shutdown(ctx))
+	require.True(t, time.Since(start) < time.Second)
+
+	for i := 0; i < 2; i++ {
+		result := <-results
+
+		require.Error(t, result.Error())
+		require.Contains(t, result.Error().Error(), "context canceled")
+	}
+
+	result := client.GET("/fast", nil)
+
+	require.Error(t, result.Error())
+	require.Contains(t, result.Error().Error(), "client is shut down")
+}
+
+func TestShutdownRawBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[`))
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	reader, err := client.GET("/", nil, WithRawBody()).BodyReader()
+	require.NoError(t, err)
+
+	defer reader.Close()
+
+	require.NoError(t, client.Shutdown(context.Background()))
+
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err)
+}
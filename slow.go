@@ -0,0 +1,37 @@
+package restclient
+
+import "time"
+
+// SlowRequestInfo describe a request which exceeded the slow request threshold
+type SlowRequestInfo struct {
+	Method  string
+	URL     string
+	Elapsed time.Duration // including retries
+}
+
+type slowRequest struct {
+	threshold time.Duration
+	notify    func(info SlowRequestInfo)
+}
+
+// WithSlowRequestThreshold call fn after any request of the client which took longer than d
+func WithSlowRequestThreshold(d time.Duration, fn func(info SlowRequestInfo)) ClientOption {
+	return func(client *clientImpl) {
+		client.slow.threshold = d
+		client.slow.notify = fn
+	}
+}
+
+func (client *clientImpl) checkSlow(method string, url string, start time.Time) {
+	if client.slow.notify == nil {
+		return
+	}
+
+	if elapsed := client.clock.Now().Sub(start); elapsed > client.slow.threshold {
+		client.slow.notify(SlowRequestInfo{
+			Method:  method,
+			URL:     url,
+			Elapsed: elapsed,
+		})
+	}
+}
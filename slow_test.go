@@ -0,0 +1,37 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSlowRequestThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	var slows []SlowRequestInfo
+
+	client := New(server.URL, WithSlowRequestThreshold(50*time.Millisecond, func(info SlowRequestInfo) {
+		slows = append(slows, info)
+	}))
+
+	require.True(t, client.GET("/fast", nil).OK())
+	require.Empty(t, slows)
+
+	require.True(t, client.GET("/slow", nil).OK())
+	require.Len(t, slows, 1)
+	require.Equal(t, http.MethodGet, slows[0].Method)
+	require.Equal(t, server.URL+"/slow", slows[0].URL)
+	require.True(t, slows[0].Elapsed >= 100*time.Millisecond)
+}
@@ -0,0 +1,94 @@
+package restclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// ClientStats client counters, a NewConns count growing with Requests means
+// connections are not kept alive. BytesRead and BytesWritten count the connections of the
+// default transport only, round trippers set with WithSchemeTransport are not counted
+type ClientStats struct {
+	Requests     int64 // requests sent, each retry attempt counts
+	NewConns     int64 // requests sent over a newly dialed connection
+	ReusedConns  int64 // requests sent over a pooled connection
+	BytesRead    int64 // read from the connections, headers and tls included
+	BytesWritten int64 // written to the connections, headers and tls included
+}
+
+type clientStats struct {
+	requests     int64
+	newConns     int64
+	reusedConns  int64
+	bytesRead    int64
+	bytesWritten int64
+}
+
+// countedConn connection adding the bytes read and written to the stats
+type countedConn struct {
+	net.Conn
+	stats *clientStats
+}
+
+func (conn *countedConn) Read(p []byte) (int, error) {
+	n, err := conn.Conn.Read(p)
+	atomic.AddInt64(&conn.stats.bytesRead, int64(n))
+
+	return n, err
+}
+
+func (conn *countedConn) Write(p []byte) (int, error) {
+	n, err := conn.Conn.Write(p)
+	atomic.AddInt64(&conn.stats.bytesWritten, int64(n))
+
+	return n, err
+}
+
+// countBytes wrap the connections dialed by base to count their bytes
+func (stats *clientStats) countBytes(base *http.Transport) {
+	dial := base.DialContext
+
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	base.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &countedConn{Conn: conn, stats: stats}, nil
+	}
+}
+
+func (stats *clientStats) request() {
+	atomic.AddInt64(&stats.requests, 1)
+}
+
+// trace bind the connection tracing hooks to ctx
+func (stats *clientStats) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddInt64(&stats.reusedConns, 1)
+			} else {
+				atomic.AddInt64(&stats.newConns, 1)
+			}
+		},
+	})
+}
+
+func (client *clientImpl) Stats() ClientStats {
+	return ClientStats{
+		Requests:     atomic.LoadInt64(&client.stats.requests),
+		NewConns:     atomic.LoadInt64(&client.stats.newConns),
+		ReusedConns:  atomic.LoadInt64(&client.stats.reusedConns),
+		BytesRead:    atomic.LoadInt64(&client.stats.bytesRead),
+		BytesWritten: atomic.LoadInt64(&client.stats.bytesWritten),
+	}
+}
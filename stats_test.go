@@ -0,0 +1,77 @@
+package restclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsConnReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	for i := 0; i < 3; i++ {
+		require.True(t, client.GET("/", nil).OK())
+	}
+
+	stats := client.Stats()
+
+	require.Equal(t, int64(3), stats.Requests)
+	require.Equal(t, int64(1), stats.NewConns)
+	require.Equal(t, int64(2), stats.ReusedConns)
+}
+
+func TestWithConnectionClose(t *testing.T) {
+	var connection []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connection = append(connection, r.Header.Get("Connection"))
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.True(t, client.GET("/", nil).OK())
+	require.True(t, client.GET("/", nil, WithConnectionClose()).OK())
+	require.True(t, client.GET("/", nil).OK())
+
+	stats := client.Stats()
+
+	require.Equal(t, int64(2), stats.NewConns)
+	require.Equal(t, int64(1), stats.ReusedConns)
+	require.Equal(t, []string{"", "close", ""}, connection)
+}
+
+func TestStatsBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[1,2,3]}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.True(t, client.POST("/", map[string]string{"name": "a"}).OK())
+
+	first := client.Stats()
+
+	// headers included, so more than the bodies
+	require.True(t, first.BytesRead > int64(len(`{"items":[1,2,3]}`)))
+	require.True(t, first.BytesWritten > int64(len(`{"name":"a"}`)))
+
+	require.True(t, client.GET("/", nil).OK())
+
+	second := client.Stats()
+
+	require.True(t, second.BytesRead > first.BytesRead)
+	require.True(t, second.BytesWritten > first.BytesWritten)
+}
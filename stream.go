@@ -0,0 +1,137 @@
+package restclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// WithRawBody leave the response body unread, read it through Result.BodyReader
+func WithRawBody() Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).rawBody = true
+	}
+}
+
+// BodyReader the response body of a request issued WithRawBody, read on demand instead of
+// buffered. the caller must close the reader. for a buffered response the reader reads
+// the buffered body, for a failed request the raw body is closed and the error returned
+func (result *resultImpl) BodyReader() (io.ReadCloser, error) {
+	var body io.ReadCloser
+
+	if result.resp != nil && result.resp.RawBody() != nil && result.settings.rawBody {
+		body = result.resp.RawBody()
+	}
+
+	if err := result.Error(); err != nil {
+		if body != nil {
+			body.Close()
+		}
+
+		return nil, err
+	}
+
+	if body == nil {
+		return ioutil.NopCloser(bytes.NewReader(result.Bytes())), nil
+	}
+
+	return body, nil
+}
+
+// StreamChan issue the GET request and decode the response body record by record into the
+// returned channel, the body is either a json array or newline delimited json. the body is
+// read on demand, a slow consumer slows the read down instead of buffering the body.
+//
+// both channels are closed when the body is consumed, the first error is delivered on the
+// error channel. cancelling ctx stops the read
+func StreamChan[T any](ctx context.Context, c Client, path string, request interface{}, options ...Option) (<-chan T, <-chan error) {
+	items := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(items)
+
+		options = append([]Option{WithContext(ctx)}, append(options[:len(options):len(options)], WithRawBody())...)
+
+		result := c.GET(path, request, options...)
+
+		if resp := result.Response(); resp != nil && resp.RawBody() != nil {
+			defer resp.RawBody().Close()
+		}
+
+		if err := result.Error(); err != nil {
+			errs <- err
+			return
+		}
+
+		reader := bufio.NewReader(result.Response().RawBody())
+
+		array, err := isJSONArray(reader)
+
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		decoder := json.NewDecoder(reader)
+
+		if array {
+			if _, err := decoder.Token(); err != nil {
+				errs <- fmt.Errorf("decode stream err %s", err)
+				return
+			}
+		}
+
+		for decoder.More() {
+			var item T
+
+			if err := decoder.Decode(&item); err != nil {
+				errs <- fmt.Errorf("decode stream err %s", err)
+				return
+			}
+
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+
+		if array {
+			if _, err := decoder.Token(); err != nil {
+				errs <- fmt.Errorf("decode stream err %s", err)
+			}
+		}
+	}()
+
+	return items, errs
+}
+
+// isJSONArray peek the first non space byte of reader
+func isJSONArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.ReadByte()
+
+		if err == io.EOF {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("decode stream err %s", err)
+		}
+
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return b == '[', reader.UnreadByte()
+	}
+}
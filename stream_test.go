@@ -0,0 +1,170 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type streamRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newStreamServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/array":
+			fmt.Fprint(w, `[{"id":1,"name":"a"}, {"id":2,"name":"b"}, {"id":3,"name":"c"}]`)
+		case "/ndjson":
+			for i := 1; i <= 3; i++ {
+				fmt.Fprintf(w, "{\"id\":%d,\"name\":\"%c\"}\n", i, 'a'+i-1)
+				w.(http.Flusher).Flush()
+			}
+		case "/endless":
+			for i := 0; ; i++ {
+				if _, err := fmt.Fprintf(w, "{\"id\":%d}\n", i); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestStreamChan(t *testing.T) {
+	server := newStreamServer()
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	expect := []streamRecord{{1, "a"}, {2, "b"}, {3, "c"}}
+
+	for _, path := range []string{"/array", "/ndjson"} {
+		items, errs := StreamChan[streamRecord](context.Background(), client, path, nil)
+
+		var records []streamRecord
+
+		for item := range items {
+			records = append(records, item)
+		}
+
+		require.NoError(t, <-errs)
+		require.Equal(t, expect, records)
+	}
+
+	// the caller options are not written to
+	marker := func(request *http.Request) {}
+	options := make([]Option, 1, 2)
+	options[0] = marker
+
+	items, errs := StreamChan[streamRecord](context.Background(), client, "/array", nil, options...)
+
+	for range items {
+	}
+
+	require.NoError(t, <-errs)
+	require.Nil(t, options[:2][1])
+}
+
+func TestStreamChanError(t *testing.T) {
+	server := newStreamServer()
+
+	defer server.Close()
+
+	items, errs := StreamChan[streamRecord](context.Background(), New(server.URL), "/missing", nil)
+
+	for range items {
+		t.Fatal("unexpected item")
+	}
+
+	require.Error(t, <-errs)
+}
+
+func TestStreamChanCancel(t *testing.T) {
+	server := newStreamServer()
+
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	defer cancel()
+
+	items, errs := StreamChan[streamRecord](ctx, New(server.URL), "/endless", nil)
+
+	for item := range items {
+		if item.ID == 10 {
+			cancel()
+			break
+		}
+	}
+
+	for range items {
+	}
+
+	require.Error(t, <-errs)
+}
+
+func TestBodyReader(t *testing.T) {
+	chunk := strings.Repeat("x", 64*1024)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		for i := 0; i < 128; i++ {
+			fmt.Fprint(w, chunk)
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	result := client.GET("/large", nil, WithRawBody())
+
+	reader, err := result.BodyReader()
+	require.NoError(t, err)
+
+	defer reader.Close()
+
+	require.Empty(t, result.Bytes())
+
+	buff := make([]byte, 32*1024)
+	total := 0
+
+	for {
+		n, err := reader.Read(buff)
+		total += n
+
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 128*len(chunk), total)
+
+	_, err = client.GET("/missing", nil, WithRawBody()).BodyReader()
+	require.Error(t, err)
+
+	reader, err = client.GET("/large", nil).BodyReader()
+	require.NoError(t, err)
+
+	body, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Len(t, body, 128*len(chunk))
+}
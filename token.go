@@ -0,0 +1,71 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TokenSource provide the bearer token of a client, e.g. an oauth2 access token
+type TokenSource interface {
+	// Token the current token
+	Token() (string, error)
+	// Refresh drop the current token and fetch a new one
+	Refresh() (string, error)
+}
+
+// WithTokenSource send the token of source as the bearer Authorization of every request.
+// a request answered with 401 is sent once more after refreshing the token, with the same
+// context so the deadline is shared. it installs the 401 status handler of the client, a 401
+// handler set before is called with the results which are not sent again
+func WithTokenSource(source TokenSource) ClientOption {
+	return func(client *clientImpl) {
+		prev := client.statusHandlers[http.StatusUnauthorized]
+
+		next := func(result Result) Result {
+			if prev == nil {
+				return result
+			}
+
+			return prev(result)
+		}
+
+		client.options = append(client.options, func(request *http.Request) {
+			token, err := source.Token()
+
+			if err != nil {
+				settings := settingsOf(request.Context())
+
+				if settings.err == nil {
+					settings.err = fmt.Errorf("get token err %s", err)
+				}
+
+				return
+			}
+
+			request.Header.Set("Authorization", "Bearer "+token)
+		})
+
+		WithStatusHandler(http.StatusUnauthorized, func(result Result) Result {
+			impl, ok := result.(*resultImpl)
+
+			if !ok || impl.settings.tokenRefreshed {
+				return next(result)
+			}
+
+			// the request context is released once sent, its deadline tells whether time is left
+			if deadline, ok := impl.resp.Request.Context().Deadline(); ok && !client.clock.Now().Before(deadline) {
+				return next(result)
+			}
+
+			token, err := source.Refresh()
+
+			if err != nil {
+				return newResult(fmt.Errorf("refresh token err %s", err), impl.resp)
+			}
+
+			return result.Replay(WithAuthorization("Bearer "+token), func(request *http.Request) {
+				settingsOf(request.Context()).tokenRefreshed = true
+			})
+		})(client)
+	}
+}
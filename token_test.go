@@ -0,0 +1,100 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTokenSource struct {
+	token     int
+	refreshes int
+}
+
+func (source *testTokenSource) Token() (string, error) {
+	return fmt.Sprintf("t%d", source.token), nil
+}
+
+func (source *testTokenSource) Refresh() (string, error) {
+	source.refreshes++
+	source.token++
+
+	return source.Token()
+}
+
+func TestWithTokenSource(t *testing.T) {
+	var authorization []string
+
+	valid := "Bearer t2"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+
+		if r.Header.Get("Authorization") != valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	source := &testTokenSource{token: 1}
+
+	client := New(server.URL, WithTokenSource(source))
+
+	require.True(t, client.GET("/", nil).OK())
+	require.True(t, client.GET("/", nil).OK())
+
+	require.Equal(t, []string{"Bearer t1", "Bearer t2", "Bearer t2"}, authorization)
+	require.Equal(t, 1, source.refreshes)
+
+	// a refreshed token which is still rejected is not retried again
+	valid = "Bearer never"
+	authorization = nil
+
+	result := client.GET("/", nil)
+
+	require.True(t, result.Is(http.StatusUnauthorized))
+	require.Equal(t, []string{"Bearer t2", "Bearer t3"}, authorization)
+	require.Equal(t, 2, source.refreshes)
+}
+
+func TestWithTokenSourceChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	defer server.Close()
+
+	var handled int
+
+	source := &testTokenSource{token: 1}
+	clock := newFakeClock()
+
+	client := New(server.URL, WithStatusHandler(http.StatusUnauthorized, func(result Result) Result {
+		handled++
+		return result
+	}), WithTokenSource(source), withClock(clock))
+
+	// the handler set before gets the result of the retry
+	require.True(t, client.GET("/", nil).Is(http.StatusUnauthorized))
+	require.Equal(t, 1, source.refreshes)
+	require.Equal(t, 1, handled)
+
+	// past the deadline by the client clock the token is not refreshed
+	clock.now = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.True(t, client.GET("/", nil, WithContext(ctx)).Is(http.StatusUnauthorized))
+	require.Equal(t, 1, source.refreshes)
+	require.Equal(t, 2, handled)
+}
@@ -0,0 +1,285 @@
+package restclient
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// transport the client round tripper, applying the per request transport settings
+// on top of the base transport
+type transport struct {
+	base        http.RoundTripper
+	schemes     map[string]http.RoundTripper // overrides base by url scheme
+	rawBytes    bool                         // keep compressed response bodies compressed
+	lenientGzip bool                         // decompress gzip itself, taking bodies which aren't gzip as-is
+
+	tailoredMutex sync.Mutex
+	tailored      map[tailorKey]*http.Transport // clones with per request settings
+}
+
+type tailorKey struct {
+	base           *http.Transport
+	expectContinue time.Duration
+	override       TransportOverride
+}
+
+// hiddenContentEncoding carries the Content-Encoding past resty, which would decompress gzip
+const hiddenContentEncoding = "X-Restclient-Content-Encoding"
+
+func newTransport() *transport {
+	return &transport{
+		base: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+// roundTripper the transport sending request
+func (t *transport) roundTripper(request *http.Request) http.RoundTripper {
+	if base, ok := t.schemes[request.URL.Scheme]; ok {
+		return base
+	}
+
+	return t.base
+}
+
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	settings := settingsOf(request.Context())
+
+	base := t.roundTripper(request)
+
+	if settings.expectContinue > 0 || settings.transportOverride != nil {
+		base = t.tailor(base, settings)
+	}
+
+	if settings.readTimeout <= 0 {
+		return t.roundTrip(base, request)
+	}
+
+	ctx, cancel := context.WithCancel(request.Context())
+
+	resp, err := t.roundTrip(base, request.WithContext(ctx))
+
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+
+	body := &deadlineBody{
+		ReadCloser: resp.Body,
+		cancel:     cancel,
+		timeout:    settings.readTimeout,
+	}
+
+	body.timer = time.AfterFunc(settings.readTimeout, func() {
+		atomic.StoreInt32(&body.expired, 1)
+		cancel()
+	})
+
+	resp.Body = body
+
+	return resp, nil
+}
+
+// roundTrip send request through base, decoding the response body as configured
+func (t *transport) roundTrip(base http.RoundTripper, request *http.Request) (*http.Response, error) {
+	if !t.lenientGzip || t.rawBytes {
+		return t.hideEncoding(base.RoundTrip(request))
+	}
+
+	// ask for gzip like the transport would, so the body reaches lenientGunzip undecoded
+	if request.Header.Get("Accept-Encoding") == "" && request.Header.Get("Range") == "" {
+		request = request.Clone(request.Context())
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	resp, err := base.RoundTrip(request)
+
+	if err != nil {
+		return resp, err
+	}
+
+	if err := lenientGunzip(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// tailor a clone of base with the per request transport settings, kept to pool its connections
+func (t *transport) tailor(base http.RoundTripper, settings *requestSettings) http.RoundTripper {
+	httpTransport, ok := base.(*http.Transport)
+
+	if !ok {
+		return base
+	}
+
+	key := tailorKey{base: httpTransport, expectContinue: settings.expectContinue}
+
+	if settings.transportOverride != nil {
+		key.override = *settings.transportOverride
+	}
+
+	t.tailoredMutex.Lock()
+	defer t.tailoredMutex.Unlock()
+
+	if clone, ok := t.tailored[key]; ok {
+		return clone
+	}
+
+	if t.tailored == nil {
+		t.tailored = make(map[tailorKey]*http.Transport)
+	}
+
+	clone := httpTransport.Clone()
+
+	if key.expectContinue > 0 {
+		clone.ExpectContinueTimeout = key.expectContinue
+	}
+
+	key.override.apply(clone)
+
+	t.tailored[key] = clone
+
+	return clone
+}
+
+func (t *transport) hideEncoding(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil || !t.rawBytes {
+		return resp, err
+	}
+
+	if encoding := resp.Header.Get("Content-Encoding"); encoding != "" {
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Set(hiddenContentEncoding, encoding)
+	}
+
+	return resp, nil
+}
+
+// restoreEncoding put the Content-Encoding hidden by the transport back
+func (t *transport) restoreEncoding(resp *http.Response) {
+	if resp == nil || !t.rawBytes {
+		return
+	}
+
+	if encoding := resp.Header.Get(hiddenContentEncoding); encoding != "" {
+		resp.Header.Del(hiddenContentEncoding)
+		resp.Header.Set("Content-Encoding", encoding)
+	}
+}
+
+// deadlineBody response body which is aborted once the read timeout elapses
+type deadlineBody struct {
+	io.ReadCloser
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	timeout time.Duration
+	expired int32
+}
+
+func (body *deadlineBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+
+	if err != nil && err != io.EOF && atomic.LoadInt32(&body.expired) == 1 {
+		return n, fmt.Errorf("read response body timeout after %s", body.timeout)
+	}
+
+	return n, err
+}
+
+func (body *deadlineBody) Close() error {
+	body.timer.Stop()
+	body.cancel()
+
+	return body.ReadCloser.Close()
+}
+
+// WithReadTimeout bound the time spent reading the response body to d, counted from the
+// response headers, against servers which send the headers fast but trickle the body
+func WithReadTimeout(d time.Duration) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).readTimeout = d
+	}
+}
+
+// WithSchemeTransport send the requests to scheme urls, e.g. "https", through rt instead of
+// the default transport
+func WithSchemeTransport(scheme string, rt http.RoundTripper) ClientOption {
+	return func(client *clientImpl) {
+		if client.transport.schemes == nil {
+			client.transport.schemes = make(map[string]http.RoundTripper)
+		}
+
+		client.transport.schemes[strings.ToLower(scheme)] = rt
+	}
+}
+
+// WithDisableCompression stop advertising gzip and return compressed response bodies as sent,
+// e.g. to forward them unchanged. Content-Encoding is kept in the response headers
+func WithDisableCompression() ClientOption {
+	return func(client *clientImpl) {
+		if base, ok := client.transport.base.(*http.Transport); ok {
+			base.DisableCompression = true
+		}
+
+		client.transport.rawBytes = true
+	}
+}
+
+// WithExpectContinueTimeout send the body with "Expect: 100-continue", waiting up to d for the
+// server to answer 100 Continue before sending the body anyway
+func WithExpectContinueTimeout(d time.Duration) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).expectContinue = d
+		request.Header.Set("Expect", "100-continue")
+	}
+}
+
+// TransportOverride transport settings of a single request, the zero value of a field keeps
+// the client setting
+type TransportOverride struct {
+	ResponseHeaderTimeout time.Duration // max wait for the response headers once the request is written
+	TLSHandshakeTimeout   time.Duration
+	DisableKeepAlives     bool // close the connection after the request
+	InsecureSkipVerify    bool // don't verify the server certificate, for test endpoints only
+}
+
+func (override TransportOverride) apply(clone *http.Transport) {
+	if override.ResponseHeaderTimeout > 0 {
+		clone.ResponseHeaderTimeout = override.ResponseHeaderTimeout
+	}
+
+	if override.TLSHandshakeTimeout > 0 {
+		clone.TLSHandshakeTimeout = override.TLSHandshakeTimeout
+	}
+
+	if override.DisableKeepAlives {
+		clone.DisableKeepAlives = true
+	}
+
+	if override.InsecureSkipVerify {
+		if clone.TLSClientConfig == nil {
+			clone.TLSClientConfig = &tls.Config{}
+		}
+
+		clone.TLSClientConfig.InsecureSkipVerify = true
+	}
+}
+
+// WithTransportOverride send the request through a clone of the client transport with override
+// applied. a clone is built once per distinct override and keeps its own connection pool, so
+// the requests of an override don't share connections with the others. only the default
+// transport can be overridden, a request sent through a WithSchemeTransport round tripper is
+// sent unchanged. the overall timeout of a request is set with WithContext instead
+func WithTransportOverride(override TransportOverride) Option {
+	return func(request *http.Request) {
+		settingsOf(request.Context()).transportOverride = &override
+	}
+}
@@ -0,0 +1,191 @@
+package restclient
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithReadTimeout(t *testing.T) {
+	stall := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items":[`))
+		w.(http.Flusher).Flush()
+
+		if r.URL.Path == "/stall" {
+			<-stall
+		}
+
+		w.Write([]byte(`]}`))
+	}))
+
+	defer server.Close()
+	defer close(stall)
+
+	client := New(server.URL)
+
+	start := time.Now()
+
+	result := client.GET("/stall", nil, WithReadTimeout(100*time.Millisecond))
+
+	require.Error(t, result.Error())
+	require.Contains(t, result.Error().Error(), "read response body timeout")
+	require.True(t, time.Since(start) < time.Second)
+
+	require.True(t, client.GET("/fast", nil, WithReadTimeout(time.Second)).OK())
+}
+
+type recordTransport struct {
+	sync.Mutex
+	name string
+	urls []string
+}
+
+func (rt *recordTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	rt.Lock()
+	rt.urls = append(rt.urls, request.URL.String())
+	rt.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"via":"` + rt.name + `"}`)),
+		Request:    request,
+	}, nil
+}
+
+func TestWithSchemeTransport(t *testing.T) {
+	plain := &recordTransport{name: "plain"}
+	hardened := &recordTransport{name: "hardened"}
+
+	options := []ClientOption{WithSchemeTransport("http", plain), WithSchemeTransport("HTTPS", hardened)}
+
+	var via string
+
+	require.NoError(t, New("http://internal.test", options...).GET("/", nil).Value("via", &via))
+	require.Equal(t, "plain", via)
+
+	require.NoError(t, New("https://external.test", options...).GET("/", nil).Value("via", &via))
+	require.Equal(t, "hardened", via)
+
+	require.Equal(t, []string{"http://internal.test/"}, plain.urls)
+	require.Equal(t, []string{"https://external.test/"}, hardened.urls)
+}
+
+// newSlowContinueServer answer 100 Continue only after delay, the response tells whether
+// the body arrived before that
+func newSlowContinueServer(t *testing.T, delay time.Duration) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				reader := bufio.NewReader(conn)
+
+				request, err := http.ReadRequest(reader)
+
+				if err != nil {
+					return
+				}
+
+				time.Sleep(delay)
+
+				conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+				_, err = reader.Peek(1)
+				early := err == nil
+				conn.SetReadDeadline(time.Time{})
+
+				if !early {
+					fmt.Fprint(conn, "HTTP/1.1 100 Continue\r\n\r\n")
+				}
+
+				body, _ := ioutil.ReadAll(request.Body)
+
+				response := fmt.Sprintf(`{"early":%t,"body":%q}`, early, body)
+
+				fmt.Fprintf(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s", len(response), response)
+			}()
+		}
+	}()
+
+	return "http://" + listener.Addr().String(), func() { listener.Close() }
+}
+
+func TestWithExpectContinueTimeout(t *testing.T) {
+	url, stop := newSlowContinueServer(t, 200*time.Millisecond)
+
+	defer stop()
+
+	client := New(url)
+
+	var reply struct {
+		Early bool   `json:"early"`
+		Body  string `json:"body"`
+	}
+
+	require.NoError(t, client.POST("/", "payload", WithExpectContinueTimeout(20*time.Millisecond)).Into(&reply))
+	require.True(t, reply.Early)
+	require.Equal(t, "payload", reply.Body)
+
+	require.NoError(t, client.POST("/", "payload", WithExpectContinueTimeout(2*time.Second)).Into(&reply))
+	require.False(t, reply.Early)
+	require.Equal(t, "payload", reply.Body)
+}
+
+func TestWithTransportOverride(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL)
+
+	require.Error(t, client.GET("/", nil).Error())
+
+	insecure := WithTransportOverride(TransportOverride{InsecureSkipVerify: true})
+
+	require.True(t, client.GET("/", nil, insecure).OK())
+	require.True(t, client.GET("/", nil, insecure).OK())
+
+	require.Equal(t, int64(1), client.Stats().ReusedConns)
+
+	noKeepAlive := WithTransportOverride(TransportOverride{InsecureSkipVerify: true, DisableKeepAlives: true})
+
+	require.True(t, client.GET("/", nil, noKeepAlive).OK())
+	require.True(t, client.GET("/", nil, noKeepAlive).OK())
+
+	require.Equal(t, int64(1), client.Stats().ReusedConns)
+
+	headerTimeout := WithTransportOverride(TransportOverride{InsecureSkipVerify: true, ResponseHeaderTimeout: 50 * time.Millisecond})
+
+	result := client.GET("/slow", nil, headerTimeout)
+
+	require.Error(t, result.Error())
+	require.Contains(t, result.Error().Error(), "timeout awaiting response headers")
+
+	require.True(t, client.GET("/slow", nil, insecure).OK())
+}
@@ -0,0 +1,109 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// UploadResumable POST size bytes of r to path in chunks of chunkSize bytes, each chunk carries
+// a Content-Range header "bytes first-last/size". a chunk answered with 2xx or 308 is done,
+// a chunk failing with a retryable transport error, 408, 429 or 5xx is sent again up to the
+// client retry count (see WithRetry) and budget while the chunks already done are kept, until
+// the request context is done. the result of the last chunk is returned, or the result of the
+// chunk which failed
+func (client *clientImpl) UploadResumable(path string, r io.ReaderAt, size int64, chunkSize int64, options ...Option) Result {
+	if chunkSize <= 0 {
+		chunkSize = size
+	}
+
+	buff := make([]byte, chunkSize)
+
+	var result Result
+
+	for offset := int64(0); offset < size || result == nil; offset += chunkSize {
+		n := chunkSize
+
+		if offset+n > size {
+			n = size - offset
+		}
+
+		if _, err := r.ReadAt(buff[:n], offset); err != nil && err != io.EOF {
+			return newResult(fmt.Errorf("read upload chunk at %d err %s", offset, err), nil)
+		}
+
+		result = client.uploadChunk(path, buff[:n], offset, size, options)
+
+		if !chunkDone(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+// uploadChunk send one chunk, retrying it on its own
+func (client *clientImpl) uploadChunk(path string, chunk []byte, offset int64, size int64, options []Option) Result {
+	contentRange := func(request *http.Request) {
+		request.Header.Set("Content-Type", "application/octet-stream")
+
+		if len(chunk) > 0 {
+			request.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size))
+		} else {
+			request.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		}
+	}
+
+	var ctx context.Context = context.Background()
+
+	// capture the context chosen by the caller options
+	bind := func(request *http.Request) {
+		ctx = request.Context()
+	}
+
+	options = append(options[:len(options):len(options)], contentRange, WithNoRetry(), bind)
+
+	for attempt := 0; ; attempt++ {
+		result := client.POST(path, chunk, options...)
+
+		if chunkDone(result) || attempt >= client.retry.count || ctx.Err() != nil || !client.chunkRetryable(result) {
+			return result
+		}
+
+		if client.retry.budget != nil && !client.retry.budget.withdraw(client.clock.Now()) {
+			return result
+		}
+
+		select {
+		case <-client.clock.After(client.retry.wait):
+		case <-ctx.Done():
+			return result
+		}
+	}
+}
+
+func chunkDone(result Result) bool {
+	resp := result.Response()
+
+	return result.OK() || (resp != nil && resp.StatusCode() == http.StatusPermanentRedirect)
+}
+
+// chunkRetryable a chunk failing with a retryable transport error (see WithRetryableErrors),
+// 408, 429 or 5xx. errors raised before the chunk was sent are not retried
+func (client *clientImpl) chunkRetryable(result Result) bool {
+	resp := result.Response()
+
+	if resp == nil || resp.RawResponse == nil {
+		impl, ok := result.(*resultImpl)
+
+		return ok && resp != nil && transportErrorKind(impl.err)&client.retry.retryable != 0
+	}
+
+	switch code := resp.StatusCode(); {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+		return true
+	}
+
+	return false
+}
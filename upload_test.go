@@ -0,0 +1,122 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadResumable(t *testing.T) {
+	var mutex sync.Mutex
+
+	var ranges []string
+
+	uploaded := map[int64][]byte{}
+	failed := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var first, last, size int64
+
+		_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &first, &last, &size)
+		require.NoError(t, err)
+
+		ranges = append(ranges, r.Header.Get("Content-Range"))
+
+		// the second chunk fails once
+		if first == 4 && !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		uploaded[first] = body
+
+		if last+1 < size {
+			w.WriteHeader(http.StatusPermanentRedirect)
+			return
+		}
+
+		fmt.Fprintf(w, `{"size":%d}`, size)
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithRetry(2, 10*time.Millisecond))
+
+	data := "0123456789"
+
+	result := client.UploadResumable("/upload", strings.NewReader(data), int64(len(data)), 4)
+
+	require.NoError(t, result.Error())
+
+	var size int
+
+	require.NoError(t, result.Value("size", &size))
+	require.Equal(t, len(data), size)
+
+	require.Equal(t, []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 4-7/10", "bytes 8-9/10"}, ranges)
+	require.Equal(t, data, string(uploaded[0])+string(uploaded[4])+string(uploaded[8]))
+}
+
+func TestUploadResumableGivesUp(t *testing.T) {
+	var calls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	defer server.Close()
+
+	result := New(server.URL, WithRetry(1, time.Millisecond)).UploadResumable("/upload", strings.NewReader("abcdef"), 6, 2)
+
+	require.Error(t, result.Error())
+	require.Equal(t, 2, calls)
+}
+
+func TestUploadResumableRetryStops(t *testing.T) {
+	var calls int
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	client := New(server.URL, WithRetry(5, time.Hour))
+
+	// a done context stops the retries without waiting
+	result := client.UploadResumable("/upload", strings.NewReader("abcdef"), 6, 2, WithContext(ctx))
+
+	require.Error(t, result.Error())
+	require.Equal(t, 1, calls)
+
+	// an error raised before sending is not retried
+	result = client.UploadResumable("/upload", strings.NewReader("abcdef"), 6, 2, WithReferer("::"))
+
+	require.Error(t, result.Error())
+	require.Equal(t, 1, calls)
+
+	// a transport error out of the retryable kinds is not retried
+	server.Close()
+
+	result = New(server.URL, WithRetry(5, time.Hour), WithRetryableErrors(DNSError)).UploadResumable("/upload", strings.NewReader("abcdef"), 6, 2)
+
+	require.Error(t, result.Error())
+}